@@ -2,17 +2,30 @@ package helpers
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 // TryReceive waits for a value from the channel and returns (value, true, false) if
 // successful; (<empty>, false, false) if the timeout expired first; or
-// (<empty>, false, true) if the channel was closed.
+// (<empty>, false, true) if the channel was closed. The timeout is scaled by ScaledTimeout.
 func TryReceive[V any](ch <-chan V, timeout time.Duration) (V, bool, bool) {
-	deadline := time.NewTimer(timeout)
+	return TryReceiveWithClock(RealClock(), ch, timeout)
+}
+
+// TryReceiveWithClock is the same as TryReceive, but uses the specified Clock to measure
+// the timeout. The timeout is not scaled if the Clock is a FakeClock.
+func TryReceiveWithClock[V any](clock Clock, ch <-chan V, timeout time.Duration) (V, bool, bool) {
+	return tryReceive(clock, ch, scaledTimeoutForClock(clock, timeout))
+}
+
+func tryReceive[V any](clock Clock, ch <-chan V, timeout time.Duration) (V, bool, bool) {
+	deadline := clock.NewTimer(timeout)
 	defer deadline.Stop()
 	select {
 	case v, ok := <-ch:
@@ -20,7 +33,7 @@ func TryReceive[V any](ch <-chan V, timeout time.Duration) (V, bool, bool) {
 			return v, true, false
 		}
 		return v, false, true
-	case <-deadline.C:
+	case <-deadline.C():
 		var empty V
 		return empty, false, false
 	}
@@ -32,7 +45,22 @@ func RequireValue[V any](t require.TestingT, ch <-chan V, timeout time.Duration,
 	if t, ok := t.(interface{ Helper() }); ok {
 		t.Helper()
 	}
-	v, ok, closed := TryReceive(ch, timeout)
+	return RequireValueWithClock(t, RealClock(), ch, timeout, customMessageAndArgs...)
+}
+
+// RequireValueWithClock is the same as RequireValue, but uses the specified Clock to measure
+// the timeout.
+func RequireValueWithClock[V any](
+	t require.TestingT,
+	clock Clock,
+	ch <-chan V,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) V {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	v, ok, closed := TryReceiveWithClock(clock, ch, timeout)
 	if ok {
 		return v
 	}
@@ -41,13 +69,84 @@ func RequireValue[V any](t require.TestingT, ch <-chan V, timeout time.Duration,
 		failWithMessageAndArgs(t, customMessageAndArgs,
 			"expected a %T value from channel but the channel was closed", empty)
 	} else {
-		failWithMessageAndArgs(t, customMessageAndArgs,
-			"expected a %T value from channel but did not receive one in %s", empty, timeout)
+		failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+			"expected a %T value from channel but did not receive one in %s",
+			empty, describeTimeoutForClock(clock, timeout))
 	}
 	t.FailNow()
 	return empty // never reached
 }
 
+// RequireValueMatching receives values from the channel, discarding any that do not pass the
+// matcher, until it receives one that does; it returns that value. If the timeout expires or
+// the channel is closed first, it forces an immediate test failure and exit. The failure message
+// lists every value that was discarded, and why the matcher rejected it.
+//
+//	event := helpers.RequireValueMatching(t, eventsCh, matchers.Not(matchers.Equal(heartbeat)), time.Second)
+func RequireValueMatching[V any](
+	t require.TestingT,
+	ch <-chan V,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) V {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	var discarded []string
+	for {
+		v, ok, closed := tryReceive(RealClock(), ch, time.Until(deadline))
+		if ok {
+			pass, desc := matcher.Test(v)
+			if pass {
+				return v
+			}
+			discarded = append(discarded, describeDiscardedValue(v, desc))
+			continue
+		}
+		var empty V
+		if closed {
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"expected a matching %T value from channel but the channel was closed; %s",
+				empty, describeDiscardedValues(discarded))
+		} else {
+			failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+				"expected a matching %T value from channel but did not receive one in %s; %s",
+				empty, describeTimeout(timeout), describeDiscardedValues(discarded))
+		}
+		t.FailNow()
+		return empty // never reached
+	}
+}
+
+// AssertNoValueMatching asserts that no value passing the matcher is received from the channel
+// within the timeout. Any values that do not pass the matcher are consumed and ignored. It is not
+// a failure for the channel to be closed.
+func AssertNoValueMatching[V any](
+	t assert.TestingT,
+	ch <-chan V,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	for {
+		v, ok, _ := tryReceive(RealClock(), ch, time.Until(deadline))
+		if !ok {
+			return true
+		}
+		if pass, _ := matcher.Test(v); pass {
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"expected no matching %T values from channel but got one: %s", v, matchers.DescribeValue(v))
+			return false
+		}
+	}
+}
+
 // AssertNoMoreValues asserts that no value is available from the channel within the timeout,
 // but that the channel was not closed.
 func AssertNoMoreValues[V any](
@@ -59,7 +158,22 @@ func AssertNoMoreValues[V any](
 	if t, ok := t.(interface{ Helper() }); ok {
 		t.Helper()
 	}
-	v, ok, closed := TryReceive(ch, timeout)
+	return AssertNoMoreValuesWithClock(t, RealClock(), ch, timeout, customMessageAndArgs...)
+}
+
+// AssertNoMoreValuesWithClock is the same as AssertNoMoreValues, but uses the specified Clock
+// to measure the timeout.
+func AssertNoMoreValuesWithClock[V any](
+	t assert.TestingT,
+	clock Clock,
+	ch <-chan V,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	v, ok, closed := TryReceiveWithClock(clock, ch, timeout)
 	if ok {
 		failWithMessageAndArgs(t, customMessageAndArgs,
 			"expected no more %T values from channel but got one: %+v", v, v)
@@ -82,15 +196,30 @@ func AssertChannelClosed[V any](
 	if t, ok := t.(interface{ Helper() }); ok {
 		t.Helper()
 	}
-	v, ok, closed := TryReceive(ch, timeout)
+	return AssertChannelClosedWithClock(t, RealClock(), ch, timeout, customMessageAndArgs...)
+}
+
+// AssertChannelClosedWithClock is the same as AssertChannelClosed, but uses the specified Clock
+// to measure the timeout.
+func AssertChannelClosedWithClock[V any](
+	t assert.TestingT,
+	clock Clock,
+	ch <-chan V,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	v, ok, closed := TryReceiveWithClock(clock, ch, timeout)
 	if ok {
 		failWithMessageAndArgs(t, customMessageAndArgs,
 			"expected no more %T values from channel but got one: %+v", v, v)
 		return false
 	}
 	if !closed {
-		failWithMessageAndArgs(t, customMessageAndArgs,
-			"expected channel to be closed within %s but it was not", timeout)
+		failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+			"expected channel to be closed within %s but it was not", describeTimeoutForClock(clock, timeout))
 		return false
 	}
 	return true
@@ -107,7 +236,22 @@ func AssertChannelNotClosed[V any](
 	if t, ok := t.(interface{ Helper() }); ok {
 		t.Helper()
 	}
-	deadline := time.NewTimer(timeout)
+	return AssertChannelNotClosedWithClock(t, RealClock(), ch, timeout, customMessageAndArgs...)
+}
+
+// AssertChannelNotClosedWithClock is the same as AssertChannelNotClosed, but uses the specified
+// Clock to measure the timeout.
+func AssertChannelNotClosedWithClock[V any](
+	t assert.TestingT,
+	clock Clock,
+	ch <-chan V,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := clock.NewTimer(scaledTimeoutForClock(clock, timeout))
 	defer deadline.Stop()
 	for {
 		select {
@@ -116,15 +260,84 @@ func AssertChannelNotClosed[V any](
 				failWithMessageAndArgs(t, customMessageAndArgs, "channel was unexpectedly closed")
 				return false
 			}
-		case <-deadline.C:
+		case <-deadline.C():
 			return true
 		}
 	}
 }
 
+// FailureOption is an optional parameter that changes how a helper reports a failure. It can be
+// passed anywhere in the customMessageAndArgs parameter of any helper that has one, and is not
+// treated as part of the custom message.
+//
+//	helpers.RequireValue(t, ch, time.Second, helpers.FailureOptionDumpGoroutines())
+type FailureOption interface {
+	apply(c *failureConfig)
+}
+
+type failureConfig struct {
+	dumpGoroutines bool
+}
+
+type dumpGoroutinesFailureOption struct{}
+
+func (o dumpGoroutinesFailureOption) apply(c *failureConfig) {
+	c.dumpGoroutines = true
+}
+
+// FailureOptionDumpGoroutines returns an option that, if a helper fails because a timeout expired,
+// adds the stack traces of all goroutines that are running code from the module under test to the
+// failure message. This can help to diagnose a deadlock.
+func FailureOptionDumpGoroutines() FailureOption {
+	return dumpGoroutinesFailureOption{}
+}
+
 func failWithMessageAndArgs(t assert.TestingT, customMessageAndArgs []any, defaultMsg string, defaultArgs ...any) {
+	customMessageAndArgs, _ = parseFailureOptions(customMessageAndArgs)
 	t.Errorf(defaultMsg, defaultArgs...)
 	if len(customMessageAndArgs) != 0 {
 		t.Errorf(fmt.Sprintf("%s", customMessageAndArgs[0]), customMessageAndArgs[1:]...)
 	}
 }
+
+// failWithTimeoutMessageAndArgs is the same as failWithMessageAndArgs, but is used when the
+// failure is due to a timeout, so that FailureOptionDumpGoroutines can take effect.
+func failWithTimeoutMessageAndArgs(
+	t assert.TestingT,
+	customMessageAndArgs []any,
+	defaultMsg string,
+	defaultArgs ...any,
+) {
+	customMessageAndArgs, config := parseFailureOptions(customMessageAndArgs)
+	msg := fmt.Sprintf(defaultMsg, defaultArgs...)
+	if config.dumpGoroutines {
+		msg += "\n\n" + describeModuleGoroutines(getCurrentGoroutineID())
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs, "%s", msg)
+}
+
+func parseFailureOptions(customMessageAndArgs []any) ([]any, failureConfig) {
+	var config failureConfig
+	var rest []any
+	for _, a := range customMessageAndArgs {
+		if o, ok := a.(FailureOption); ok {
+			o.apply(&config)
+		} else {
+			rest = append(rest, a)
+		}
+	}
+	return rest, config
+}
+
+func describeDiscardedValue(value any, failureDesc string) string {
+	// Matcher.Test appends a description of the full value, which we are already showing
+	reason, _, _ := strings.Cut(failureDesc, "\nfull value was: ")
+	return fmt.Sprintf("%s (%s)", matchers.DescribeValue(value), reason)
+}
+
+func describeDiscardedValues(discarded []string) string {
+	if len(discarded) == 0 {
+		return "no values were received"
+	}
+	return fmt.Sprintf("discarded %d non-matching value(s):\n%s", len(discarded), strings.Join(discarded, "\n"))
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
 	"github.com/launchdarkly/go-test-helpers/v3/testbox"
 
 	"github.com/stretchr/testify/assert"
@@ -110,6 +111,7 @@ func TestAssertChannelNotClosed(t *testing.T) {
 }
 
 func TestFailureMessages(t *testing.T) {
+	withTimeoutScale(t, 1)
 	result := testbox.SandboxTest(func(t testbox.TestingT) {
 		ch := make(chan string, 1)
 		_ = RequireValue(t, ch, time.Millisecond, "sorry%s", ".")
@@ -164,3 +166,166 @@ func TestFailureMessages(t *testing.T) {
 		assert.Equal(t, "sorry.", result.Failures[1].Message)
 	}
 }
+
+func TestChannelHelpersWithFakeClock(t *testing.T) {
+	advanceWhenWaiting := func(clock *FakeClock, d time.Duration) {
+		go func() {
+			clock.BlockUntilWaiters(1)
+			clock.Advance(d)
+		}()
+	}
+
+	t.Run("TryReceiveWithClock times out in virtual time", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ch := make(chan string)
+		advanceWhenWaiting(clock, time.Hour)
+		_, ok, closed := TryReceiveWithClock(clock, ch, time.Hour)
+		assert.False(t, ok)
+		assert.False(t, closed)
+	})
+
+	t.Run("RequireValueWithClock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ch := make(chan string, 1)
+		ch <- "a"
+		assert.Equal(t, "a", RequireValueWithClock(t, clock, ch, time.Hour))
+
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			advanceWhenWaiting(clock, time.Hour)
+			_ = RequireValueWithClock(t, clock, ch, time.Hour)
+		})
+		if assert.Len(t, result.Failures, 1) {
+			assert.Equal(t, "expected a string value from channel but did not receive one in 1h0m0s",
+				result.Failures[0].Message)
+		}
+	})
+
+	t.Run("AssertNoMoreValuesWithClock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ch := make(chan string, 1)
+		advanceWhenWaiting(clock, time.Hour)
+		AssertNoMoreValuesWithClock(t, clock, ch, time.Hour)
+
+		testbox.ShouldFail(t, func(t testbox.TestingT) {
+			ch <- "a"
+			AssertNoMoreValuesWithClock(t, clock, ch, time.Hour)
+		})
+	})
+
+	t.Run("AssertChannelClosedWithClock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		testbox.ShouldFail(t, func(t testbox.TestingT) {
+			advanceWhenWaiting(clock, time.Hour)
+			AssertChannelClosedWithClock(t, clock, make(chan string), time.Hour)
+		})
+
+		ch := make(chan string)
+		close(ch)
+		AssertChannelClosedWithClock(t, clock, ch, time.Hour)
+	})
+
+	t.Run("AssertChannelNotClosedWithClock", func(t *testing.T) {
+		clock := NewFakeClock(time.Now())
+		ch := make(chan string, 1)
+		ch <- "a"
+		advanceWhenWaiting(clock, time.Hour)
+		AssertChannelNotClosedWithClock(t, clock, ch, time.Hour)
+	})
+}
+
+func TestRequireValueMatching(t *testing.T) {
+	withTimeoutScale(t, 1)
+	ch := make(chan string, 10)
+	ch <- "heartbeat"
+	ch <- "a"
+	assert.Equal(t, "a", RequireValueMatching(t, ch, matchers.Not(matchers.Equal("heartbeat")), time.Second))
+
+	testbox.ShouldFailAndExitEarly(t, func(t testbox.TestingT) {
+		_ = RequireValueMatching(t, make(chan string), matchers.Equal("a"), time.Millisecond)
+	})
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string, 10)
+		ch <- "b"
+		ch <- "c"
+		_ = RequireValueMatching(t, ch, matchers.Equal("a"), time.Millisecond*10, "sorry%s", ".")
+	})
+	if assert.Len(t, result.Failures, 2) {
+		assert.Equal(t, "expected a matching string value from channel but did not receive one in 10ms; "+
+			"discarded 2 non-matching value(s):\n"+
+			`"b" (did not equal "a")`+"\n"+
+			`"c" (did not equal "a")`, result.Failures[0].Message)
+		assert.Equal(t, "sorry.", result.Failures[1].Message)
+	}
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string)
+		close(ch)
+		_ = RequireValueMatching(t, ch, matchers.Equal("a"), time.Second)
+	})
+	if assert.Len(t, result.Failures, 1) {
+		assert.Equal(t, "expected a matching string value from channel but the channel was closed; "+
+			"no values were received", result.Failures[0].Message)
+	}
+}
+
+func TestAssertNoValueMatching(t *testing.T) {
+	ch := make(chan string, 10)
+	ch <- "b"
+	AssertNoValueMatching(t, ch, matchers.Equal("a"), time.Millisecond*10)
+
+	closedCh := make(chan string)
+	close(closedCh)
+	AssertNoValueMatching(t, closedCh, matchers.Equal("a"), time.Second)
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string, 10)
+		ch <- "b"
+		ch <- "a"
+		AssertNoValueMatching(t, ch, matchers.Equal("a"), time.Second)
+	})
+	if assert.Len(t, result.Failures, 1) {
+		assert.Equal(t, `expected no matching string values from channel but got one: "a"`, result.Failures[0].Message)
+	}
+}
+
+func TestFailureOptionDumpGoroutines(t *testing.T) {
+	withTimeoutScale(t, 1)
+	release := make(chan struct{})
+	defer close(release)
+	go leakyGoroutine(release)
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string)
+		_ = RequireValue(t, ch, time.Millisecond, FailureOptionDumpGoroutines(), "custom %s", "message")
+	})
+	assert.True(t, result.Failed)
+	if assert.Len(t, result.Failures, 2) {
+		assert.Regexp(t, `^expected a string value from channel but did not receive one in 1ms\n\n`+
+			`\d+ goroutine\(s\) running code from github.com/launchdarkly/go-test-helpers/v3:\n\n`,
+			result.Failures[0].Message)
+		assert.Contains(t, result.Failures[0].Message, "v3.leakyGoroutine(")
+		assert.Equal(t, "custom message", result.Failures[1].Message)
+	}
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string)
+		AssertChannelClosed(t, ch, time.Millisecond, FailureOptionDumpGoroutines())
+	})
+	assert.True(t, result.Failed)
+	if assert.Len(t, result.Failures, 1) {
+		assert.Contains(t, result.Failures[0].Message, "v3.leakyGoroutine(")
+	}
+}
+
+func TestFailureOptionDumpGoroutinesIsIgnoredForFailureOtherThanTimeout(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		ch := make(chan string)
+		close(ch)
+		_ = RequireValue(t, ch, time.Second, FailureOptionDumpGoroutines())
+	})
+	assert.True(t, result.Failed)
+	if assert.Len(t, result.Failures, 1) {
+		assert.Equal(t, "expected a string value from channel but the channel was closed", result.Failures[0].Message)
+	}
+}
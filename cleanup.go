@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+// CleanupT is the subset of testing.TB methods that is needed by helpers which register actions
+// to be taken at the end of a test. It is satisfied by *testing.T, *testing.B, and testbox.CleanupT
+// (see testbox.SandboxTestWithCleanup).
+type CleanupT interface {
+	require.TestingT
+	// Cleanup registers a function to be called when the test completes.
+	Cleanup(f func())
+}
+
+// WithCleanupScope calls the action with a CleanupT that is not tied to any test, and then calls
+// any cleanup functions that were registered on it, in reverse order of registration.
+//
+// This allows helpers that take a CleanupT, such as TempFile, to be used in a callback style
+// outside of a test function. Since there is no test to fail, errors are handled as follows: if
+// FailNow is called, a panic is raised with an error containing whatever was passed to Errorf,
+// since the calling code cannot continue; any errors reported with Errorf but not followed by
+// FailNow, such as failures in cleanup functions, are logged with log.Printf.
+//
+//	helpers.WithCleanupScope(func(t helpers.CleanupT) {
+//	    dir := helpers.TempDir(t)
+//	    DoSomethingWithDir(dir)
+//	}) // the directory is deleted at the end of this block
+func WithCleanupScope(action func(t CleanupT)) {
+	s := &cleanupScope{}
+	defer s.runCleanups()
+	action(s)
+	s.logErrors()
+}
+
+type cleanupScope struct {
+	cleanups []func()
+	errors   []string
+}
+
+func (s *cleanupScope) Errorf(format string, args ...any) {
+	s.errors = append(s.errors, fmt.Sprintf(format, args...))
+}
+
+func (s *cleanupScope) FailNow() {
+	err := errors.New(strings.Join(s.errors, "\n"))
+	s.errors = nil
+	panic(err)
+}
+
+func (s *cleanupScope) Cleanup(f func()) {
+	s.cleanups = append(s.cleanups, f)
+}
+
+func (s *cleanupScope) logErrors() {
+	for _, e := range s.errors {
+		log.Printf("%s", e)
+	}
+	s.errors = nil
+}
+
+func (s *cleanupScope) runCleanups() {
+	var firstPanic any
+	for len(s.cleanups) != 0 {
+		f := s.cleanups[len(s.cleanups)-1]
+		s.cleanups = s.cleanups[:len(s.cleanups)-1]
+		func() {
+			defer func() {
+				if r := recover(); r != nil && firstPanic == nil {
+					firstPanic = r
+				}
+			}()
+			f()
+		}()
+		s.logErrors()
+	}
+	if firstPanic != nil {
+		panic(firstPanic)
+	}
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureLogOutput(action func()) string {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	action()
+	return buf.String()
+}
+
+func TestWithCleanupScopeRunsCleanupsInReverseOrder(t *testing.T) {
+	var calls []string
+	WithCleanupScope(func(t CleanupT) {
+		t.Cleanup(func() { calls = append(calls, "first") })
+		t.Cleanup(func() { calls = append(calls, "second") })
+		calls = append(calls, "action")
+	})
+	assert.Equal(t, []string{"action", "second", "first"}, calls)
+}
+
+func TestWithCleanupScopeLogsErrors(t *testing.T) {
+	output := captureLogOutput(func() {
+		WithCleanupScope(func(t CleanupT) {
+			t.Cleanup(func() { t.Errorf("cleanup error %d", 2) })
+			t.Errorf("action error %d", 1)
+		})
+	})
+	assert.Regexp(t, "action error 1\n.*cleanup error 2\n$", output)
+}
+
+func TestWithCleanupScopePanicsOnFailNow(t *testing.T) {
+	cleanedUp := false
+	r := func() (r any) {
+		defer func() { r = recover() }()
+		WithCleanupScope(func(t CleanupT) {
+			t.Cleanup(func() { cleanedUp = true })
+			t.Errorf("bad thing")
+			t.FailNow()
+		})
+		return nil
+	}()
+	require.NotNil(t, r)
+	err, ok := r.(error)
+	require.True(t, ok)
+	assert.Equal(t, errors.New("bad thing"), err)
+	assert.True(t, cleanedUp)
+}
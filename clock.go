@@ -0,0 +1,264 @@
+package helpers
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is an abstraction of the time-related functions used by helpers in this package, so that
+// tests can substitute virtual time for real time. RealClock returns the standard implementation;
+// FakeClock is a controllable implementation for deterministic tests.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer is equivalent to time.NewTimer.
+	NewTimer(d time.Duration) Timer
+	// NewTicker is equivalent to time.NewTicker.
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc is equivalent to time.AfterFunc. The returned Timer's C method returns nil.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is the subset of time.Timer functionality that is provided by Clock.
+type Timer interface {
+	// C returns the channel on which the time is delivered when the timer fires.
+	C() <-chan time.Time
+	// Stop is equivalent to time.Timer.Stop.
+	Stop() bool
+	// Reset is equivalent to time.Timer.Reset.
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the subset of time.Ticker functionality that is provided by Clock.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop is equivalent to time.Ticker.Stop.
+	Stop()
+	// Reset is equivalent to time.Ticker.Reset.
+	Reset(d time.Duration)
+}
+
+type realClock struct{}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// RealClock returns a Clock that simply delegates to the standard time package.
+func RealClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                   { return time.Now() }
+func (realClock) NewTimer(d time.Duration) Timer   { return realTimer{time.NewTimer(d)} }
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{time.AfterFunc(d, f)}
+}
+
+func (r realTimer) C() <-chan time.Time        { return r.timer.C }
+func (r realTimer) Stop() bool                 { return r.timer.Stop() }
+func (r realTimer) Reset(d time.Duration) bool { return r.timer.Reset(d) }
+
+func (r realTicker) C() <-chan time.Time   { return r.ticker.C }
+func (r realTicker) Stop()                 { r.ticker.Stop() }
+func (r realTicker) Reset(d time.Duration) { r.ticker.Reset(d) }
+
+// FakeClock is a Clock whose time only changes when the test calls Advance. Timers, tickers, and
+// AfterFunc callbacks fire during Advance, in order of their deadlines, if the new time has
+// reached their deadlines.
+//
+// Since code under test usually creates its timers on another goroutine, BlockUntilWaiters can be
+// used to make sure that a timer exists before advancing the clock:
+//
+//	clock := helpers.NewFakeClock(time.Now())
+//	go func() {
+//	    clock.BlockUntilWaiters(1)
+//	    clock.Advance(time.Second)
+//	}()
+//	helpers.AssertNoMoreValuesWithClock(t, clock, ch, time.Second) // returns without real delay
+type FakeClock struct {
+	now     time.Time
+	waiters []*fakeWaiter
+	changed chan struct{}
+	lock    sync.Mutex
+}
+
+type fakeWaiter struct {
+	clock    *FakeClock
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	fn       func()
+}
+
+type fakeTimer struct {
+	*fakeWaiter
+}
+
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+// NewFakeClock creates a FakeClock whose current time is initially the specified time.
+func NewFakeClock(startTime time.Time) *FakeClock {
+	return &FakeClock{now: startTime}
+}
+
+// Now returns the clock's current virtual time.
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// NewTimer creates a Timer that fires when the clock has been advanced by at least d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return fakeTimer{w}
+}
+
+// NewTicker creates a Ticker that fires every time the clock has been advanced by another d. As
+// with time.Ticker, ticks are dropped if the receiver has not consumed the previous one.
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	w := &fakeWaiter{clock: c, ch: make(chan time.Time, 1), period: d}
+	c.schedule(w, d)
+	return fakeTicker{w}
+}
+
+// AfterFunc arranges for f to be called when the clock has been advanced by at least d. Unlike
+// time.AfterFunc, f is called synchronously on the goroutine that called Advance.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	w := &fakeWaiter{clock: c, fn: f}
+	c.schedule(w, d)
+	return fakeTimer{w}
+}
+
+// Advance moves the clock's time forward by d, firing any timers, tickers, and AfterFunc callbacks
+// whose deadlines are reached. While each one is being fired, Now returns its deadline.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	target := c.now.Add(d)
+	for {
+		sort.SliceStable(c.waiters, func(i, j int) bool {
+			return c.waiters[i].deadline.Before(c.waiters[j].deadline)
+		})
+		if len(c.waiters) == 0 || c.waiters[0].deadline.After(target) {
+			break
+		}
+		w := c.waiters[0]
+		if w.deadline.After(c.now) {
+			c.now = w.deadline
+		}
+		if w.period > 0 {
+			w.deadline = w.deadline.Add(w.period)
+		} else {
+			c.removeLocked(w)
+		}
+		if w.ch != nil {
+			select {
+			case w.ch <- c.now:
+			default:
+			}
+		}
+		if w.fn != nil {
+			c.lock.Unlock()
+			w.fn()
+			c.lock.Lock()
+		}
+	}
+	if target.After(c.now) {
+		c.now = target
+	}
+	c.lock.Unlock()
+}
+
+// BlockUntilWaiters blocks until at least n timers, tickers, or AfterFunc callbacks are
+// waiting on the clock.
+func (c *FakeClock) BlockUntilWaiters(n int) {
+	for {
+		c.lock.Lock()
+		if len(c.waiters) >= n {
+			c.lock.Unlock()
+			return
+		}
+		if c.changed == nil {
+			c.changed = make(chan struct{})
+		}
+		changed := c.changed
+		c.lock.Unlock()
+		<-changed
+	}
+}
+
+func (c *FakeClock) schedule(w *fakeWaiter, d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	w.deadline = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	c.notifyLocked()
+}
+
+func (c *FakeClock) unschedule(w *fakeWaiter) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.removeLocked(w)
+}
+
+func (c *FakeClock) removeLocked(w *fakeWaiter) bool {
+	for i, w1 := range c.waiters {
+		if w1 == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.notifyLocked()
+			return true
+		}
+	}
+	return false
+}
+
+func (c *FakeClock) notifyLocked() {
+	if c.changed != nil {
+		close(c.changed)
+		c.changed = nil
+	}
+}
+
+func (t fakeTimer) C() <-chan time.Time { return t.ch }
+
+func (t fakeTimer) Stop() bool {
+	return t.clock.unschedule(t.fakeWaiter)
+}
+
+func (t fakeTimer) Reset(d time.Duration) bool {
+	active := t.clock.unschedule(t.fakeWaiter)
+	t.clock.schedule(t.fakeWaiter, d)
+	return active
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.ch }
+
+func (t fakeTicker) Stop() {
+	t.clock.unschedule(t.fakeWaiter)
+}
+
+func (t fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock ticker Reset")
+	}
+	t.clock.unschedule(t.fakeWaiter)
+	t.clock.lock.Lock()
+	t.period = d
+	t.clock.lock.Unlock()
+	t.clock.schedule(t.fakeWaiter, d)
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealClock(t *testing.T) {
+	clock := RealClock()
+	assert.WithinDuration(t, time.Now(), clock.Now(), time.Second)
+
+	timer := clock.NewTimer(time.Millisecond)
+	_, ok, _ := TryReceive(timer.C(), time.Second)
+	assert.True(t, ok)
+
+	ticker := clock.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	_, ok, _ = TryReceive(ticker.C(), time.Second)
+	assert.True(t, ok)
+
+	called := make(chan struct{})
+	clock.AfterFunc(time.Millisecond, func() { close(called) })
+	AssertChannelClosed(t, called, time.Second)
+}
+
+func TestFakeClockTimer(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	timer := clock.NewTimer(time.Second)
+	clock.Advance(time.Second - 1)
+	AssertNoMoreValues(t, timer.C(), time.Millisecond)
+
+	clock.Advance(1)
+	assert.Equal(t, start.Add(time.Second), RequireValue(t, timer.C(), time.Second))
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	clock.Advance(time.Hour)
+	AssertNoMoreValues(t, timer.C(), time.Millisecond)
+	assert.Equal(t, start.Add(time.Hour+time.Second), clock.Now())
+}
+
+func TestFakeClockTicker(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	ticker := clock.NewTicker(time.Second)
+
+	clock.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), RequireValue(t, ticker.C(), time.Second))
+
+	clock.Advance(time.Second * 3) // extra ticks are dropped, as with time.Ticker
+	assert.Equal(t, start.Add(time.Second*2), RequireValue(t, ticker.C(), time.Second))
+	AssertNoMoreValues(t, ticker.C(), time.Millisecond)
+
+	ticker.Reset(time.Minute)
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Second*4+time.Minute), RequireValue(t, ticker.C(), time.Second))
+
+	ticker.Stop()
+	clock.Advance(time.Hour)
+	AssertNoMoreValues(t, ticker.C(), time.Millisecond)
+}
+
+func TestFakeClockAfterFunc(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	var calledAt []time.Time
+	clock.AfterFunc(time.Second*2, func() { calledAt = append(calledAt, clock.Now()) })
+	clock.AfterFunc(time.Second, func() { calledAt = append(calledAt, clock.Now()) })
+	stopped := clock.AfterFunc(time.Second, func() { calledAt = append(calledAt, clock.Now()) })
+	assert.True(t, stopped.Stop())
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, []time.Time{start.Add(time.Second), start.Add(time.Second * 2)}, calledAt)
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestFakeClockBlockUntilWaiters(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	unblocked := make(chan struct{})
+	go func() {
+		clock.BlockUntilWaiters(2)
+		close(unblocked)
+	}()
+	clock.NewTimer(time.Second)
+	AssertChannelNotClosed(t, unblocked, time.Millisecond*20)
+	clock.NewTicker(time.Second)
+	AssertChannelClosed(t, unblocked, time.Second)
+}
@@ -1,10 +1,28 @@
 package helpers
 
 import (
+	"errors"
+	"fmt"
 	"io"
-	"log"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
 )
 
+// CloseOnCleanup ensures that the given object's Close() method is called when the test completes.
+// If closing the object fails, the test fails.
+//
+//	client := NewClient()
+//	helpers.CloseOnCleanup(t, client)
+func CloseOnCleanup(t CleanupT, closeableObject io.Closer) {
+	t.Cleanup(func() {
+		if err := closeableObject.Close(); err != nil {
+			t.Errorf("failed to close %T: %s", closeableObject, err)
+		}
+	})
+}
+
 // WithCloser executes a function and ensures that the given object's Close() method is always called afterward.
 //
 // This is simply a way to get more specific control over an object's lifetime than using defer. A test function
@@ -13,11 +31,105 @@ import (
 //
 // If closing the object fails, an error is logged.
 func WithCloser(closeableObject io.Closer, action func()) {
-	defer func() {
-		err := closeableObject.Close()
-		if err != nil {
-			log.Printf("failed to close %T: %s", closeableObject, err)
+	WithCleanupScope(func(t CleanupT) {
+		CloseOnCleanup(t, closeableObject)
+		action()
+	})
+}
+
+// ErrCloseTimeout is wrapped by the errors that Closers.CloseAll returns for a closer that did not
+// finish closing within the timeout.
+var ErrCloseTimeout = errors.New("timed out while closing")
+
+// Closers is a stack of resources that need to be closed at the end of a test, such as servers,
+// streams, and clients. CloseAll closes them in the reverse of the order they were added, so that
+// a resource is closed before anything it depends on.
+//
+// The zero value is an empty stack. A Closers is safe for concurrent use.
+//
+//	var closers helpers.Closers
+//	server := httptest.NewServer(handler)
+//	closers.PushFunc(func() error { server.Close(); return nil })
+//	client := NewClient(server.URL)
+//	closers.Push(client)
+//	...
+//	assert.NoError(t, closers.CloseAll(time.Second))
+type Closers struct {
+	closers []namedCloser
+	lock    sync.Mutex
+}
+
+type namedCloser struct {
+	name  string
+	close func() error
+}
+
+// Push adds an io.Closer to the stack.
+func (c *Closers) Push(closer io.Closer) {
+	c.push(namedCloser{name: fmt.Sprintf("%T", closer), close: closer.Close})
+}
+
+// PushFunc adds a function to the stack, to be called as if it were the Close method of an
+// io.Closer. In error messages, it is identified by its function name.
+func (c *Closers) PushFunc(closeFn func() error) {
+	name := "func"
+	if fn := runtime.FuncForPC(reflect.ValueOf(closeFn).Pointer()); fn != nil {
+		name = fn.Name()
+	}
+	c.push(namedCloser{name: name, close: closeFn})
+}
+
+// CloseAll closes everything on the stack in last-in-first-out order, and then empties the stack.
+//
+// Each closer is allowed to take up to the specified timeout. If it does not finish by then,
+// CloseAll moves on to the next one, leaving the hung closer running on its own goroutine; the
+// error for that closer wraps ErrCloseTimeout and includes a dump of all goroutine stacks, to help
+// find out what it was waiting for. A panic in a closer is also treated as an error.
+//
+// The return value is nil if everything was closed successfully, or else an errors.Join of all of
+// the failures, in the order they happened.
+func (c *Closers) CloseAll(timeout time.Duration) error {
+	c.lock.Lock()
+	closers := c.closers
+	c.closers = nil
+	c.lock.Unlock()
+
+	var errs []error
+	for i := len(closers) - 1; i >= 0; i-- {
+		if err := closeWithTimeout(closers[i], timeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Closers) push(nc namedCloser) {
+	c.lock.Lock()
+	c.closers = append(c.closers, nc)
+	c.lock.Unlock()
+}
+
+func closeWithTimeout(nc namedCloser, timeout time.Duration) error {
+	result := make(chan error, 1) // buffered so a hung closer's goroutine can still exit later
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic while closing %s: %v", nc.name, r)
+			}
+		}()
+		if err := nc.close(); err != nil {
+			result <- fmt.Errorf("failed to close %s: %w", nc.name, err)
+			return
 		}
+		result <- nil
 	}()
-	action()
+	timer := time.NewTimer(ScaledTimeout(timeout))
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("%s did not finish closing within %s: %w; goroutine stacks:\n\n%s",
+			nc.name, describeTimeout(timeout), ErrCloseTimeout, getStackDump(true))
+	}
 }
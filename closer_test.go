@@ -1,18 +1,24 @@
 package helpers
 
 import (
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type myCloser struct {
 	closed bool
+	err    error
 }
 
 func (m *myCloser) Close() error {
 	m.closed = true
-	return nil
+	return m.err
 }
 
 func TestWithCloser(t *testing.T) {
@@ -22,3 +28,86 @@ func TestWithCloser(t *testing.T) {
 	})
 	assert.True(t, c.closed)
 }
+
+func TestWithCloserLogsError(t *testing.T) {
+	c := &myCloser{err: errors.New("sorry")}
+	output := captureLogOutput(func() {
+		WithCloser(c, func() {})
+	})
+	assert.True(t, c.closed)
+	assert.Contains(t, output, "failed to close *helpers.myCloser: sorry")
+}
+
+func TestCloseOnCleanup(t *testing.T) {
+	c := &myCloser{}
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		CloseOnCleanup(t, c)
+		assert.False(t, c.closed)
+	})
+	assert.False(t, result.Failed)
+	assert.True(t, c.closed)
+}
+
+func TestCloseOnCleanupFailsTestIfCloseFails(t *testing.T) {
+	c := &myCloser{err: errors.New("sorry")}
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		CloseOnCleanup(t, c)
+	})
+	assert.True(t, c.closed)
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "failed to close *helpers.myCloser: sorry", result.Failures[0].Message)
+}
+
+func namedCloseFunc() error { return errors.New("from func") }
+
+func TestClosersCloseAllInReverseOrder(t *testing.T) {
+	var calls []string
+	var closers Closers
+	closers.PushFunc(func() error { calls = append(calls, "first"); return nil })
+	c := &myCloser{}
+	closers.Push(c)
+	closers.PushFunc(func() error { calls = append(calls, "third"); return nil })
+
+	assert.NoError(t, closers.CloseAll(time.Second))
+	assert.Equal(t, []string{"third", "first"}, calls)
+	assert.True(t, c.closed)
+
+	assert.NoError(t, closers.CloseAll(time.Second)) // stack is now empty
+	assert.Equal(t, []string{"third", "first"}, calls)
+}
+
+func TestClosersCloseAllAggregatesErrors(t *testing.T) {
+	myErr := errors.New("sorry")
+	var closers Closers
+	closers.Push(&myCloser{err: myErr})
+	closers.PushFunc(namedCloseFunc)
+	closers.PushFunc(func() error { panic("oops") })
+
+	err := closers.CloseAll(time.Second)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, myErr))
+	assert.Equal(t, "panic while closing github.com/launchdarkly/go-test-helpers/v3.TestClosersCloseAllAggregatesErrors.func1: oops\n"+
+		"failed to close github.com/launchdarkly/go-test-helpers/v3.namedCloseFunc: from func\n"+
+		"failed to close *helpers.myCloser: sorry",
+		err.Error())
+}
+
+func TestClosersCloseAllTimesOutHungCloser(t *testing.T) {
+	withTimeoutScale(t, 1)
+	release := make(chan struct{})
+	defer close(release)
+	closed := false
+	var closers Closers
+	closers.PushFunc(func() error { closed = true; return nil })
+	closers.Push(FaultyCloser(&myCloser{}, CloseHangs(release)))
+
+	start := time.Now()
+	err := closers.CloseAll(time.Millisecond * 50)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*50))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrCloseTimeout))
+	assert.Contains(t, err.Error(), "helpers.closerFunc did not finish closing within 50ms: timed out while closing")
+	assert.Contains(t, err.Error(), "goroutine ")
+	assert.True(t, closed) // the next closer was still called
+}
@@ -0,0 +1,210 @@
+package helpers
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const defaultConcurrencyTimeout = time.Second * 10
+
+// ConcurrencyOption is an optional parameter for RunConcurrently.
+type ConcurrencyOption interface {
+	apply(*concurrencyConfig)
+}
+
+type concurrencyConfig struct {
+	timeout    time.Duration
+	iterations int
+	maxDelay   time.Duration
+	random     *rand.Rand
+}
+
+type timeoutConcurrencyOption time.Duration
+
+func (o timeoutConcurrencyOption) apply(c *concurrencyConfig) {
+	c.timeout = time.Duration(o)
+}
+
+type iterationsConcurrencyOption int
+
+func (o iterationsConcurrencyOption) apply(c *concurrencyConfig) {
+	c.iterations = int(o)
+}
+
+type randomDelayConcurrencyOption struct {
+	maxDelay time.Duration
+	random   *rand.Rand
+}
+
+func (o randomDelayConcurrencyOption) apply(c *concurrencyConfig) {
+	c.maxDelay, c.random = o.maxDelay, o.random
+}
+
+// ConcurrencyOptionTimeout sets the maximum total time that RunConcurrently can take, for all
+// iterations. The default is 10 seconds.
+func ConcurrencyOptionTimeout(timeout time.Duration) ConcurrencyOption {
+	return timeoutConcurrencyOption(timeout)
+}
+
+// ConcurrencyOptionIterations causes RunConcurrently to repeat the whole test the specified number
+// of times, starting all of the goroutines together each time. The default is 1.
+func ConcurrencyOptionIterations(iterations int) ConcurrencyOption {
+	return iterationsConcurrencyOption(iterations)
+}
+
+// ConcurrencyOptionRandomDelay causes each goroutine in RunConcurrently to wait for a random
+// duration between zero and maxDelay, after being released from the starting barrier and before
+// calling the action, to vary the interleaving of the goroutines. The delays are taken from the
+// specified random source, so a test can reproduce them by using the same seed.
+func ConcurrencyOptionRandomDelay(maxDelay time.Duration, source *rand.Rand) ConcurrencyOption {
+	return randomDelayConcurrencyOption{maxDelay: maxDelay, random: source}
+}
+
+// RunConcurrently calls the action on n goroutines at once, passing each one its index from 0 to
+// n-1, and waits for all of them to finish. This is meant for stress-testing code for race
+// conditions, preferably with the race detector enabled.
+//
+// All of the goroutines are started first and then released from a barrier at the same moment, to
+// maximize the chance of overlap. To run the whole thing repeatedly, or to add random delays, see
+// ConcurrencyOptionIterations and ConcurrencyOptionRandomDelay.
+//
+// If any goroutine panics, or exits with runtime.Goexit, the test fails and stops immediately once
+// all of the goroutines in that iteration have finished; each panic is reported with its goroutine
+// index and stack trace. If the goroutines have not all finished before the timeout (see
+// ConcurrencyOptionTimeout), the test fails and stops immediately, reporting the stacks of the
+// goroutines that are still running; those goroutines are left running.
+//
+//	helpers.RunConcurrently(t, 20, func(i int) {
+//	    cache.Set(fmt.Sprint(i), i)
+//	    _ = cache.Get(fmt.Sprint(i))
+//	}, helpers.ConcurrencyOptionIterations(100))
+func RunConcurrently(t require.TestingT, n int, action func(i int), options ...ConcurrencyOption) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	config := concurrencyConfig{timeout: defaultConcurrencyTimeout, iterations: 1}
+	for _, o := range options {
+		o.apply(&config)
+	}
+	deadline := time.Now().Add(ScaledTimeout(config.timeout))
+	for iteration := 1; iteration <= config.iterations; iteration++ {
+		if failure := runConcurrentIteration(n, action, &config, deadline); failure != "" {
+			if config.iterations > 1 {
+				failure = fmt.Sprintf("in iteration %d of %d, %s", iteration, config.iterations, failure)
+			}
+			t.Errorf("%s", failure)
+			t.FailNow()
+			return
+		}
+	}
+}
+
+type concurrentWorker struct {
+	goroutineID int64
+	done        bool
+	failure     string
+}
+
+// runConcurrentIteration returns a failure description, or "" if successful.
+func runConcurrentIteration(n int, action func(int), config *concurrencyConfig, deadline time.Time) string {
+	delays := make([]time.Duration, n)
+	if config.random != nil && config.maxDelay > 0 {
+		for i := range delays {
+			delays[i] = time.Duration(config.random.Int63n(int64(config.maxDelay) + 1))
+		}
+	}
+
+	workers := make([]concurrentWorker, n)
+	var lock sync.Mutex
+	var ready, finished sync.WaitGroup
+	start := make(chan struct{})
+	ready.Add(n)
+	finished.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer finished.Done()
+			lock.Lock()
+			workers[i].goroutineID = getCurrentGoroutineID()
+			lock.Unlock()
+			ready.Done()
+			<-start
+			if delays[i] > 0 {
+				time.Sleep(delays[i])
+			}
+			workers[i].run(i, action, &lock)
+		}()
+	}
+	ready.Wait()
+	close(start)
+
+	allFinished := make(chan struct{})
+	go func() {
+		finished.Wait()
+		close(allFinished)
+	}()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-allFinished:
+	case <-timer.C:
+		return describeBlockedWorkers(workers, &lock, config.timeout)
+	}
+
+	var failures []string
+	for _, w := range workers {
+		if w.failure != "" {
+			failures = append(failures, w.failure)
+		}
+	}
+	return strings.Join(failures, "\n\n")
+}
+
+func (w *concurrentWorker) run(i int, action func(int), lock *sync.Mutex) {
+	returned := false
+	defer func() {
+		r := recover()
+		lock.Lock()
+		defer lock.Unlock()
+		w.done = true
+		switch {
+		case r != nil:
+			w.failure = fmt.Sprintf("goroutine %d panicked: %v\n\n%s", i, r, debug.Stack())
+		case !returned:
+			w.failure = fmt.Sprintf("goroutine %d exited without returning (was runtime.Goexit or FailNow called?)", i)
+		}
+	}()
+	action(i)
+	returned = true
+}
+
+func describeBlockedWorkers(workers []concurrentWorker, lock *sync.Mutex, timeout time.Duration) string {
+	lock.Lock()
+	running := make(map[int64]int)
+	for i, w := range workers {
+		if !w.done {
+			running[w.goroutineID] = i
+		}
+	}
+	lock.Unlock()
+	stacks := make([]string, len(workers))
+	for _, g := range getAllGoroutines() {
+		if i, ok := running[g.id]; ok {
+			stacks[i] = g.stack
+		}
+	}
+	var indexes, descriptions []string
+	for i, stack := range stacks {
+		if stack != "" {
+			indexes = append(indexes, fmt.Sprint(i))
+			descriptions = append(descriptions, fmt.Sprintf("index %d: %s", i, stack))
+		}
+	}
+	return fmt.Sprintf("timed out after %s with %d of %d goroutine(s) still running (indexes %s):\n\n%s",
+		describeTimeout(timeout), len(indexes), len(workers), strings.Join(indexes, ", "), strings.Join(descriptions, "\n\n"))
+}
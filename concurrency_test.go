@@ -0,0 +1,103 @@
+package helpers
+
+import (
+	"math/rand"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConcurrentlyCallsAllIndexes(t *testing.T) {
+	var lock sync.Mutex
+	counts := make(map[int]int)
+	RunConcurrently(t, 5, func(i int) {
+		lock.Lock()
+		counts[i]++
+		lock.Unlock()
+	}, ConcurrencyOptionIterations(3))
+	assert.Equal(t, map[int]int{0: 3, 1: 3, 2: 3, 3: 3, 4: 3}, counts)
+}
+
+func TestRunConcurrentlyStartsAllGoroutinesTogether(t *testing.T) {
+	// If the goroutines were not all running at once, this would deadlock
+	n := 10
+	var arrived sync.WaitGroup
+	arrived.Add(n)
+	RunConcurrently(t, n, func(int) {
+		arrived.Done()
+		arrived.Wait()
+	}, ConcurrencyOptionTimeout(time.Second*5))
+}
+
+func TestRunConcurrentlyReportsPanics(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RunConcurrently(t, 4, func(i int) {
+			if i%2 == 1 {
+				panic("oops")
+			}
+		})
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	msg := result.Failures[0].Message
+	assert.Regexp(t, "^goroutine 1 panicked: oops\n\ngoroutine \\d+ \\[running\\]:\n", msg)
+	assert.Contains(t, msg, "\n\ngoroutine 3 panicked: oops\n\n")
+	assert.NotContains(t, msg, "goroutine 0 panicked")
+	assert.Contains(t, msg, "concurrency_test.go")
+}
+
+func TestRunConcurrentlyReportsGoexit(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RunConcurrently(t, 2, func(i int) {
+			if i == 1 {
+				runtime.Goexit()
+			}
+		}, ConcurrencyOptionIterations(2))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "in iteration 1 of 2, goroutine 1 exited without returning (was runtime.Goexit or FailNow called?)",
+		result.Failures[0].Message)
+}
+
+func TestRunConcurrentlyTimeout(t *testing.T) {
+	withTimeoutScale(t, 1)
+	release := make(chan struct{})
+	defer close(release)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RunConcurrently(t, 3, func(i int) {
+			if i != 1 {
+				<-release
+			}
+		}, ConcurrencyOptionTimeout(time.Millisecond*50))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	msg := result.Failures[0].Message
+	assert.Regexp(t, "^timed out after 50ms with 2 of 3 goroutine\\(s\\) still running \\(indexes 0, 2\\):\n\n"+
+		"index 0: goroutine \\d+ \\[chan receive\\]:\n", msg)
+	assert.Regexp(t, "\n\nindex 2: goroutine \\d+ \\[chan receive\\]:\n", msg)
+}
+
+func TestRunConcurrentlyRandomDelay(t *testing.T) {
+	var maxElapsed atomic.Int64
+	start := time.Now()
+	RunConcurrently(t, 5, func(int) {
+		elapsed := int64(time.Since(start))
+		for {
+			old := maxElapsed.Load()
+			if elapsed <= old || maxElapsed.CompareAndSwap(old, elapsed) {
+				break
+			}
+		}
+	}, ConcurrencyOptionRandomDelay(time.Millisecond*50, rand.New(rand.NewSource(1))))
+	assert.Greater(t, maxElapsed.Load(), int64(time.Millisecond))
+}
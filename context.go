@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ErrTestCompleted is the cause of cancellation for a context created by TestContext, if it was
+// cancelled because the test completed.
+var ErrTestCompleted = errors.New("test completed")
+
+// AssertContextDone asserts that the context is done (that is, cancelled or past its deadline)
+// within the specified timeout, and that its cause, as returned by context.Cause, matches the
+// matcher. An empty matchers.Matcher{} accepts any cause.
+//
+//	helpers.AssertContextDone(t, ctx, time.Second, matchers.ErrorIs(context.DeadlineExceeded))
+func AssertContextDone(
+	t assert.TestingT,
+	ctx context.Context,
+	timeout time.Duration,
+	causeMatcher matchers.Matcher,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if !waitForSignal(ctx.Done(), time.Now().Add(ScaledTimeout(timeout))) {
+		failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+			"expected context to be done within %s, but it was not", describeTimeout(timeout))
+		return false
+	}
+	if pass, desc := causeMatcher.Test(context.Cause(ctx)); !pass {
+		failWithMessageAndArgs(t, customMessageAndArgs, "context was done, but the cause was not as expected: %s", desc)
+		return false
+	}
+	return true
+}
+
+// RequireContextDone is the same as AssertContextDone, except that it returns the context's cause,
+// or causes the test to fail and stop immediately if the context was not done or the cause did not
+// match.
+func RequireContextDone(
+	t require.TestingT,
+	ctx context.Context,
+	timeout time.Duration,
+	causeMatcher matchers.Matcher,
+	customMessageAndArgs ...any,
+) error {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if !AssertContextDone(t, ctx, timeout, causeMatcher, customMessageAndArgs...) {
+		t.FailNow()
+	}
+	return context.Cause(ctx)
+}
+
+// AssertContextNotDone asserts that the context does not become done (that is, cancelled or past
+// its deadline) within the specified duration. On failure, the message includes the context's
+// cause, as returned by context.Cause.
+func AssertContextNotDone(
+	t assert.TestingT,
+	ctx context.Context,
+	duration time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if waitForSignal(ctx.Done(), time.Now().Add(ScaledTimeout(duration))) {
+		failWithMessageAndArgs(t, customMessageAndArgs,
+			"expected context not to be done within %s, but it was done with cause: %s",
+			describeTimeout(duration), context.Cause(ctx))
+		return false
+	}
+	return true
+}
+
+// RequireContextNotDone is the same as AssertContextNotDone, except that it causes the test to fail
+// and stop immediately if the context becomes done.
+func RequireContextNotDone(
+	t require.TestingT,
+	ctx context.Context,
+	duration time.Duration,
+	customMessageAndArgs ...any,
+) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if !AssertContextNotDone(t, ctx, duration, customMessageAndArgs...) {
+		t.FailNow()
+	}
+}
+
+// TestContext returns a context that is cancelled, with the cause ErrTestCompleted, when the test
+// completes. If the test has a deadline, as *testing.T does when "go test" is run with a timeout,
+// the context also has that deadline.
+//
+//	ctx := helpers.TestContext(t)
+//	result, err := client.Query(ctx, params)
+func TestContext(t CleanupT) context.Context {
+	ctx := context.Background()
+	if td, ok := t.(interface{ Deadline() (time.Time, bool) }); ok {
+		if deadline, ok := td.Deadline(); ok {
+			var cancelDeadline context.CancelFunc
+			ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+			t.Cleanup(cancelDeadline)
+		}
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	t.Cleanup(func() { cancel(ErrTestCompleted) })
+	return ctx
+}
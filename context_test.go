@@ -0,0 +1,133 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertContextDoneSuccess(t *testing.T) {
+	myErr := errors.New("sorry")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel(myErr)
+	}()
+	assert.True(t, AssertContextDone(t, ctx, time.Second, matchers.ErrorIs(myErr)))
+	assert.True(t, AssertContextDone(t, ctx, time.Second, matchers.Matcher{}))
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel2()
+	assert.True(t, AssertContextDone(t, ctx2, time.Second, matchers.ErrorIs(context.DeadlineExceeded)))
+}
+
+func TestAssertContextDoneFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertContextDone(t, context.Background(), time.Millisecond*10, matchers.Matcher{}, "custom %s", "message")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "expected context to be done within 10ms, but it was not", result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("sorry"))
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertContextDone(t, ctx, time.Second, matchers.ErrorIs(context.DeadlineExceeded))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "context was done, but the cause was not as expected: "+
+		"was not and did not wrap error \"context deadline exceeded\"\nfull value was: sorry",
+		result.Failures[0].Message)
+}
+
+func TestAssertContextNotDone(t *testing.T) {
+	withTimeoutScale(t, 1)
+	assert.True(t, AssertContextNotDone(t, context.Background(), time.Millisecond*10))
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("sorry"))
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertContextNotDone(t, ctx, time.Second)
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected context not to be done within 1s, but it was done with cause: sorry",
+		result.Failures[0].Message)
+}
+
+func TestRequireContextDone(t *testing.T) {
+	withTimeoutScale(t, 1)
+	myErr := errors.New("sorry")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(myErr)
+	assert.Equal(t, myErr, RequireContextDone(t, ctx, time.Second, matchers.ErrorIs(myErr)))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequireContextDone(t, context.Background(), time.Millisecond*10, matchers.Matcher{})
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected context to be done within 10ms, but it was not", result.Failures[0].Message)
+}
+
+func TestRequireContextNotDone(t *testing.T) {
+	withTimeoutScale(t, 1)
+	RequireContextNotDone(t, context.Background(), time.Millisecond*10)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("sorry"))
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequireContextNotDone(t, ctx, time.Second, "custom %s", "message")
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "expected context not to be done within 1s, but it was done with cause: sorry",
+		result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+}
+
+type testingTWithDeadline struct {
+	CleanupT
+	deadline time.Time
+}
+
+func (t testingTWithDeadline) Deadline() (time.Time, bool) { return t.deadline, true }
+
+func TestTestContext(t *testing.T) {
+	var ctx context.Context
+	testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		ctx = TestContext(t)
+		_, hasDeadline := ctx.Deadline()
+		assert.False(t, hasDeadline)
+		AssertContextNotDone(t, ctx, time.Millisecond)
+	})
+	AssertContextDone(t, ctx, time.Second, matchers.ErrorIs(ErrTestCompleted))
+}
+
+func TestTestContextHasTestDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		ctx := TestContext(testingTWithDeadline{CleanupT: t, deadline: deadline})
+		actual, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		assert.Equal(t, deadline, actual)
+	})
+
+	if deadline, ok := t.Deadline(); ok {
+		actual, hasDeadline := TestContext(t).Deadline()
+		assert.True(t, hasDeadline)
+		assert.Equal(t, deadline, actual)
+	}
+}
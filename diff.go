@@ -0,0 +1,109 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+const lineDiffContextLines = 3
+
+type lineDiffOp struct {
+	kind byte // ' ' for unchanged, '-' for removed, '+' for added
+	line string
+}
+
+// describeLineDiff returns a unified diff of two multi-line strings, or "" if they are equal.
+func describeLineDiff(name1, name2, text1, text2 string) string {
+	if text1 == text2 {
+		return ""
+	}
+	ops := diffLines(splitLines(text1), splitLines(text2))
+
+	// Find the ranges of ops to show: each change plus some context, merging ranges that overlap
+	type opRange struct{ start, end int }
+	var ranges []opRange
+	for i, op := range ops {
+		if op.kind == ' ' {
+			continue
+		}
+		start, end := max(0, i-lineDiffContextLines), min(len(ops), i+lineDiffContextLines+1)
+		if len(ranges) != 0 && start <= ranges[len(ranges)-1].end {
+			ranges[len(ranges)-1].end = end
+		} else {
+			ranges = append(ranges, opRange{start, end})
+		}
+	}
+
+	// Compute the line number in each text where each op occurs
+	lineNums1, lineNums2 := make([]int, len(ops)+1), make([]int, len(ops)+1)
+	for i, op := range ops {
+		lineNums1[i+1], lineNums2[i+1] = lineNums1[i], lineNums2[i]
+		if op.kind != '+' {
+			lineNums1[i+1]++
+		}
+		if op.kind != '-' {
+			lineNums2[i+1]++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", name1, name2)
+	for _, r := range ranges {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n",
+			lineNums1[r.start]+1, lineNums1[r.end]-lineNums1[r.start],
+			lineNums2[r.start]+1, lineNums2[r.end]-lineNums2[r.start])
+		for _, op := range ops[r.start:r.end] {
+			fmt.Fprintf(&b, "%c%s\n", op.kind, op.line)
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += " (no newline at end)"
+	}
+	return lines
+}
+
+// diffLines computes a minimal edit script using the longest common subsequence of the lines.
+// This is quadratic, but the inputs in tests are small.
+func diffLines(lines1, lines2 []string) []lineDiffOp {
+	n, m := len(lines1), len(lines2)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if lines1[i] == lines2[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	ops := make([]lineDiffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && lines1[i] == lines2[j]:
+			ops = append(ops, lineDiffOp{' ', lines1[i]})
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, lineDiffOp{'-', lines1[i]})
+			i++
+		default:
+			ops = append(ops, lineDiffOp{'+', lines2[j]})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeLineDiffEqual(t *testing.T) {
+	assert.Equal(t, "", describeLineDiff("a", "b", "x\ny\n", "x\ny\n"))
+}
+
+func TestDescribeLineDiffChangedLine(t *testing.T) {
+	assert.Equal(t, `--- a
++++ b
+@@ -1,3 +1,3 @@
+ x
+-y
++z
+ w`, describeLineDiff("a", "b", "x\ny\nw\n", "x\nz\nw\n"))
+}
+
+func TestDescribeLineDiffSeparateHunks(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = string(rune('a' + i))
+	}
+	text1 := strings.Join(lines, "\n") + "\n"
+	lines[1], lines[18] = "B", "S"
+	text2 := strings.Join(lines, "\n") + "\n"
+	assert.Equal(t, `--- a
++++ b
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -16,5 +16,5 @@
+ p
+ q
+ r
+-s
++S
+ t`, describeLineDiff("a", "b", text1, text2))
+}
+
+func TestDescribeLineDiffAddedLinesAndMissingNewline(t *testing.T) {
+	assert.Equal(t, `--- a
++++ b
+@@ -1,1 +1,2 @@
+-x (no newline at end)
++x
++y`, describeLineDiff("a", "b", "x", "x\ny\n"))
+}
@@ -0,0 +1,356 @@
+package helpers
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/launchdarkly/go-test-helpers/v3/jsonhelpers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultDirTreeFileMode = 0644
+	defaultDirTreeDirMode  = 0755
+)
+
+// DirTree describes the contents of a directory tree, for use with WithTempDirTree and
+// AssertDirMatches. Each key is a path relative to the root of the tree, using "/" as a
+// separator. Parent directories do not need to be listed unless they need a specific mode.
+//
+//	tree := helpers.DirTree{
+//	    "config.json":     {Content: `{"a": 1}`},
+//	    "flags/flag1.yml": {Content: "key: flag1", Mode: 0600},
+//	    "empty":           {Mode: fs.ModeDir | 0700},
+//	    "latest":          {LinkTarget: "flags/flag1.yml"},
+//	}
+//
+// See also DirTreeOf and DirTreeFromTxtar.
+type DirTree map[string]DirTreeEntry
+
+// DirTreeEntry describes a file, directory, or symbolic link in a DirTree.
+type DirTreeEntry struct {
+	// Content is the content of a file. It is ignored for directories and symbolic links.
+	Content string
+	// Mode is the file mode. If it includes fs.ModeDir, the entry is a directory. If the
+	// permission bits are zero, the default is 0644 for files and 0755 for directories;
+	// AssertDirMatches only checks permissions if they are nonzero.
+	Mode fs.FileMode
+	// LinkTarget, if not empty, makes the entry a symbolic link pointing to that path.
+	LinkTarget string
+}
+
+// DirTreeOf creates a DirTree from a map of file paths to file contents.
+func DirTreeOf(files map[string]string) DirTree {
+	ret := make(DirTree, len(files))
+	for p, content := range files {
+		ret[p] = DirTreeEntry{Content: content}
+	}
+	return ret
+}
+
+// DirTreeFromTxtar creates a DirTree from a text archive in the txtar format used by the Go
+// tools. Each file begins with a line of the form "-- path --"; any text before the first such
+// line is treated as a comment and ignored.
+//
+//	tree := helpers.DirTreeFromTxtar(`
+//	-- config.json --
+//	{"a": 1}
+//	-- flags/flag1.yml --
+//	key: flag1
+//	`)
+func DirTreeFromTxtar(archive string) DirTree {
+	ret := make(DirTree)
+	var currentPath string
+	var content strings.Builder
+	inFile := false
+	finishFile := func() {
+		if inFile {
+			ret[currentPath] = DirTreeEntry{Content: content.String()}
+		}
+		content.Reset()
+	}
+	for _, line := range strings.SplitAfter(archive, "\n") {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(trimmed, "-- ") && strings.HasSuffix(trimmed, " --") && len(trimmed) > 6 {
+			finishFile()
+			currentPath = strings.TrimSpace(trimmed[3 : len(trimmed)-3])
+			inFile = true
+			continue
+		}
+		if inFile {
+			content.WriteString(line)
+		}
+	}
+	finishFile()
+	return ret
+}
+
+// WriteDirTree creates all of the files, directories, and symbolic links described by the DirTree,
+// under the specified root directory.
+//
+// The permissions of directories that are listed explicitly are set after all of the files have
+// been created, so a directory can be read-only even if the DirTree has files inside it.
+func WriteDirTree(root string, tree DirTree) error {
+	var dirPaths []string
+	for _, p := range tree.sortedPaths() {
+		entry := tree[p]
+		if !fs.ValidPath(p) {
+			return fmt.Errorf("invalid path in DirTree: %q", p)
+		}
+		fullPath := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(fullPath), defaultDirTreeDirMode); err != nil {
+			return err
+		}
+		switch {
+		case entry.LinkTarget != "":
+			if err := os.Symlink(filepath.FromSlash(entry.LinkTarget), fullPath); err != nil {
+				return err
+			}
+		case entry.Mode.IsDir():
+			if err := os.MkdirAll(fullPath, defaultDirTreeDirMode); err != nil {
+				return err
+			}
+			dirPaths = append(dirPaths, p)
+		default:
+			perm := entry.Mode.Perm()
+			if perm == 0 {
+				perm = defaultDirTreeFileMode
+			}
+			if err := os.WriteFile(fullPath, []byte(entry.Content), perm); err != nil {
+				return err
+			}
+			if err := os.Chmod(fullPath, perm); err != nil { // in case the umask changed the permissions
+				return err
+			}
+		}
+	}
+	// In reverse order of the sorted paths, every directory comes after the directories inside it,
+	// so making one read-only can't prevent us from changing the permissions of the others.
+	for i := len(dirPaths) - 1; i >= 0; i-- {
+		perm := tree[dirPaths[i]].Mode.Perm()
+		if perm == 0 {
+			perm = defaultDirTreeDirMode
+		}
+		if err := os.Chmod(filepath.Join(root, filepath.FromSlash(dirPaths[i])), perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDirTree reads the contents of a directory into a DirTree. Symbolic links are not followed.
+// Directories are only included if they are empty, since any other directory is implied by the
+// paths of its contents.
+func ReadDirTree(root string) (DirTree, error) {
+	ret := make(DirTree)
+	err := filepath.WalkDir(root, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if fullPath == root {
+			return nil
+		}
+		relPath, err := filepath.Rel(root, fullPath)
+		if err != nil {
+			return err
+		}
+		p := filepath.ToSlash(relPath)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case info.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(fullPath)
+			if err != nil {
+				return err
+			}
+			ret[p] = DirTreeEntry{LinkTarget: filepath.ToSlash(target), Mode: info.Mode()}
+		case info.IsDir():
+			entries, err := os.ReadDir(fullPath)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				ret[p] = DirTreeEntry{Mode: info.Mode()}
+			}
+		default:
+			data, err := os.ReadFile(fullPath)
+			if err != nil {
+				return err
+			}
+			ret[p] = DirTreeEntry{Content: string(data), Mode: info.Mode()}
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// TempDirTree creates a temporary directory containing the files described by the DirTree, and
+// returns its path. The directory is deleted when the test completes.
+//
+// If it is not possible to create the files, the test fails and stops immediately.
+//
+//	root := helpers.TempDirTree(t, helpers.DirTreeOf(map[string]string{
+//	    "a.json":    `{"a": 1}`,
+//	    "sub/b.txt": "hello",
+//	}))
+func TempDirTree(t CleanupT, tree DirTree) string {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	root := TempDir(t)
+	if err := WriteDirTree(root, tree); err != nil {
+		t.Errorf("can't create files in temp directory: %s", err)
+		t.FailNow()
+	}
+	return root
+}
+
+// WithTempDirTree creates a temporary directory containing the files described by the DirTree,
+// calls the function with the path of the directory, and then removes it.
+//
+// If for any reason it is not possible to create the files, a panic is raised since the test code
+// cannot continue.
+//
+//	helpers.WithTempDirTree(helpers.DirTreeOf(map[string]string{
+//	    "a.json":    `{"a": 1}`,
+//	    "sub/b.txt": "hello",
+//	}), func(root string) {
+//	    DoSomethingWithFiles(root)
+//	})
+//
+// To remove the directory at the end of the test instead, use TempDirTree.
+func WithTempDirTree(tree DirTree, f func(root string)) {
+	WithCleanupScope(func(t CleanupT) {
+		f(TempDirTree(t, tree))
+	})
+}
+
+// AssertDirMatches asserts that the contents of a directory are the same as the specified DirTree.
+// On failure, it reports every file that was missing, unexpected, or different. Differences in
+// files whose names end in ".json" are described with jsonhelpers.JSONDiff, so formatting
+// differences are ignored; other files are compared exactly, and differences are shown as a
+// line-by-line diff.
+func AssertDirMatches(t assert.TestingT, root string, expected DirTree, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	actual, err := ReadDirTree(root)
+	if err != nil {
+		failWithMessageAndArgs(t, customMessageAndArgs, "could not read directory %s: %s", root, err)
+		return false
+	}
+	if problems := compareDirTrees(expected, actual); len(problems) != 0 {
+		failWithMessageAndArgs(t, customMessageAndArgs, "directory %s did not have the expected contents:\n%s",
+			root, strings.Join(problems, "\n"))
+		return false
+	}
+	return true
+}
+
+// AssertDirTreeMatches is the same as AssertDirMatches, but compares two DirTree values rather than
+// reading a directory. This is useful for in-memory file systems; see ReadDirTree for the format
+// that actual should be in.
+func AssertDirTreeMatches(t assert.TestingT, actual, expected DirTree, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if problems := compareDirTrees(expected, actual); len(problems) != 0 {
+		failWithMessageAndArgs(t, customMessageAndArgs, "directory tree did not have the expected contents:\n%s",
+			strings.Join(problems, "\n"))
+		return false
+	}
+	return true
+}
+
+func compareDirTrees(expected, actual DirTree) []string {
+	var problems []string
+	for _, p := range expected.sortedPaths() {
+		e := expected[p]
+		a, found := actual[p]
+		if !found && e.isDir() && actual.hasDescendants(p) {
+			a, found = DirTreeEntry{Mode: fs.ModeDir}, true
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("missing: %s", p))
+			continue
+		}
+		if e.kind() != a.kind() {
+			problems = append(problems, fmt.Sprintf("%s: expected a %s, but found a %s", p, e.kind(), a.kind()))
+			continue
+		}
+		if e.Mode.Perm() != 0 && a.Mode.Perm() != 0 && e.Mode.Perm() != a.Mode.Perm() {
+			problems = append(problems, fmt.Sprintf("%s: expected mode %s, but was %s",
+				p, e.Mode.Perm(), a.Mode.Perm()))
+		}
+		switch {
+		case e.LinkTarget != "":
+			if e.LinkTarget != a.LinkTarget {
+				problems = append(problems, fmt.Sprintf("%s: expected link to %q, but was link to %q",
+					p, e.LinkTarget, a.LinkTarget))
+			}
+		case !e.isDir():
+			if desc := describeFileDifference(p, e.Content, a.Content); desc != "" {
+				problems = append(problems, fmt.Sprintf("%s: content differs\n%s", p, desc))
+			}
+		}
+	}
+	for _, p := range actual.sortedPaths() {
+		if _, found := expected[p]; !found && !expected.hasDescendants(p) {
+			problems = append(problems, fmt.Sprintf("unexpected: %s", p))
+		}
+	}
+	return problems
+}
+
+func describeFileDifference(p, expected, actual string) string {
+	if strings.HasSuffix(p, ".json") {
+		diff, err := jsonhelpers.JSONDiff([]byte(expected), []byte(actual))
+		if err == nil {
+			return strings.Join(diff.Describe("expected", "actual"), "\n")
+		}
+		// If either one isn't valid JSON, fall through to a regular text comparison
+	}
+	return describeLineDiff("expected", "actual", expected, actual)
+}
+
+func (t DirTree) sortedPaths() []string {
+	ret := make([]string, 0, len(t))
+	for p := range t {
+		ret = append(ret, p)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (t DirTree) hasDescendants(dirPath string) bool {
+	prefix := path.Clean(dirPath) + "/"
+	for p := range t {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e DirTreeEntry) isDir() bool {
+	return e.LinkTarget == "" && e.Mode.IsDir()
+}
+
+func (e DirTreeEntry) kind() string {
+	switch {
+	case e.LinkTarget != "" || e.Mode&fs.ModeSymlink != 0:
+		return "symbolic link"
+	case e.Mode.IsDir():
+		return "directory"
+	default:
+		return "file"
+	}
+}
@@ -0,0 +1,252 @@
+package helpers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirTreeOf(t *testing.T) {
+	assert.Equal(t,
+		DirTree{"a": {Content: "x"}, "b/c": {Content: "y"}},
+		DirTreeOf(map[string]string{"a": "x", "b/c": "y"}))
+}
+
+func TestDirTreeFromTxtar(t *testing.T) {
+	tree := DirTreeFromTxtar(`this is a comment
+-- a.json --
+{"a": 1}
+-- sub/b.txt --
+line1
+line2
+-- empty --
+`)
+	assert.Equal(t, DirTree{
+		"a.json":    {Content: "{\"a\": 1}\n"},
+		"sub/b.txt": {Content: "line1\nline2\n"},
+		"empty":     {Content: ""},
+	}, tree)
+}
+
+func TestWithTempDirTree(t *testing.T) {
+	var root string
+	WithTempDirTree(DirTree{
+		"a.txt":     {Content: "hello"},
+		"sub/b.txt": {Content: "goodbye", Mode: 0600},
+		"emptydir":  {Mode: fs.ModeDir | 0700},
+		"sub/link":  {LinkTarget: "b.txt"},
+		"sub/x/y/z": {Content: "deep"},
+	}, func(dirPath string) {
+		root = dirPath
+
+		data, err := os.ReadFile(filepath.Join(root, "a.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+
+		info, err := os.Stat(filepath.Join(root, "sub", "b.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, fs.FileMode(0600), info.Mode().Perm())
+
+		info, err = os.Stat(filepath.Join(root, "emptydir"))
+		require.NoError(t, err)
+		assert.True(t, info.IsDir())
+		assert.Equal(t, fs.FileMode(0700), info.Mode().Perm())
+
+		data, err = os.ReadFile(filepath.Join(root, "sub", "link"))
+		require.NoError(t, err)
+		assert.Equal(t, "goodbye", string(data))
+
+		data, err = os.ReadFile(filepath.Join(root, "sub", "x", "y", "z"))
+		require.NoError(t, err)
+		assert.Equal(t, "deep", string(data))
+	})
+	assert.False(t, FilePathExists(root))
+}
+
+func TestTempDirTree(t *testing.T) {
+	var root string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		root = TempDirTree(t, DirTreeOf(map[string]string{"a/b": "x"}))
+		AssertDirMatches(t, root, DirTreeOf(map[string]string{"a/b": "x"}))
+	})
+	assert.False(t, result.Failed)
+	assert.False(t, FilePathExists(root))
+
+	result = testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		TempDirTree(t, DirTreeOf(map[string]string{"../outside": "x"}))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `can't create files in temp directory: invalid path in DirTree: "../outside"`,
+		result.Failures[0].Message)
+}
+
+func TestTempDirTreeWithReadOnlyDirectory(t *testing.T) {
+	var root string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		root = TempDirTree(t, DirTree{
+			"ro":       {Mode: fs.ModeDir | 0500},
+			"ro/a":     {Content: "x"},
+			"ro/sub/b": {Content: "y"},
+			"ro/sub":   {Mode: fs.ModeDir | 0500},
+		})
+		for _, p := range []string{"ro", "ro/sub"} {
+			info, err := os.Stat(filepath.Join(root, p))
+			require.NoError(t, err)
+			assert.Equal(t, fs.ModeDir|0500, info.Mode(), p)
+		}
+		AssertDirMatches(t, root, DirTreeOf(map[string]string{"ro/a": "x", "ro/sub/b": "y"}))
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+	assert.False(t, FilePathExists(root))
+}
+
+func TestWriteDirTreeRejectsInvalidPaths(t *testing.T) {
+	WithTempDir(func(root string) {
+		assert.Error(t, WriteDirTree(root, DirTreeOf(map[string]string{"../outside": "x"})))
+		assert.Error(t, WriteDirTree(root, DirTreeOf(map[string]string{"/absolute": "x"})))
+	})
+	assert.Panics(t, func() {
+		WithTempDirTree(DirTreeOf(map[string]string{"../outside": "x"}), func(string) {})
+	})
+}
+
+func TestReadDirTree(t *testing.T) {
+	WithTempDirTree(DirTree{
+		"a.txt":     {Content: "hello"},
+		"sub/b.txt": {Content: "goodbye", Mode: 0600},
+		"emptydir":  {Mode: fs.ModeDir},
+		"link":      {LinkTarget: "a.txt"},
+	}, func(root string) {
+		tree, err := ReadDirTree(root)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "emptydir", "link", "sub/b.txt"}, tree.sortedPaths())
+		assert.Equal(t, "goodbye", tree["sub/b.txt"].Content)
+		assert.Equal(t, fs.FileMode(0600), tree["sub/b.txt"].Mode.Perm())
+		assert.True(t, tree["emptydir"].Mode.IsDir())
+		assert.Equal(t, "a.txt", tree["link"].LinkTarget)
+	})
+}
+
+func TestAssertDirMatchesSuccess(t *testing.T) {
+	tree := DirTree{
+		"a.json":    {Content: `{"a": 1, "b": [true]}`},
+		"sub/b.txt": {Content: "goodbye", Mode: 0600},
+		"emptydir":  {Mode: fs.ModeDir},
+		"link":      {LinkTarget: "a.json"},
+	}
+	WithTempDirTree(tree, func(root string) {
+		assert.True(t, AssertDirMatches(t, root, tree))
+
+		// JSON formatting differences are ignored
+		assert.True(t, AssertDirMatches(t, root, DirTree{
+			"a.json":    {Content: "{\n  \"b\": [ true ],\n  \"a\": 1\n}"},
+			"sub/b.txt": {Content: "goodbye"},
+			"emptydir":  {Mode: fs.ModeDir},
+			"link":      {LinkTarget: "a.json"},
+		}))
+
+		// a directory that is listed explicitly is satisfied by the files in it
+		assert.True(t, AssertDirMatches(t, root, DirTree{
+			"a.json":    {Content: `{"a": 1, "b": [true]}`},
+			"sub":       {Mode: fs.ModeDir},
+			"sub/b.txt": {Content: "goodbye"},
+			"emptydir":  {Mode: fs.ModeDir},
+			"link":      {LinkTarget: "a.json"},
+		}))
+	})
+}
+
+func TestAssertDirMatchesMissingAndUnexpected(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{"a": "x", "sub/c": "z"}), func(root string) {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			AssertDirMatches(t, root, DirTreeOf(map[string]string{"a": "x", "b": "y"}))
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "directory "+root+" did not have the expected contents:\nmissing: b\nunexpected: sub/c",
+			result.Failures[0].Message)
+	})
+}
+
+func TestAssertDirMatchesJSONDifference(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{"a.json": `{"a": 1, "b": 2}`}), func(root string) {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			AssertDirMatches(t, root, DirTreeOf(map[string]string{"a.json": `{"a": 1, "b": 3}`}))
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "directory "+root+" did not have the expected contents:\n"+
+			"a.json: content differs\n"+
+			`at "b": expected = 3, actual = 2`,
+			result.Failures[0].Message)
+	})
+}
+
+func TestAssertDirMatchesTextDifference(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{"a.txt": "x\ny\n"}), func(root string) {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			AssertDirMatches(t, root, DirTreeOf(map[string]string{"a.txt": "x\nz\n"}), "custom %s", "message")
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 2)
+		assert.Equal(t, "directory "+root+" did not have the expected contents:\n"+
+			"a.txt: content differs\n--- expected\n+++ actual\n@@ -1,2 +1,2 @@\n x\n-z\n+y",
+			result.Failures[0].Message)
+		assert.Equal(t, "custom message", result.Failures[1].Message)
+	})
+}
+
+func TestAssertDirMatchesModeAndKindDifferences(t *testing.T) {
+	WithTempDirTree(DirTree{
+		"a":    {Content: "x", Mode: 0600},
+		"b":    {Content: "y"},
+		"link": {LinkTarget: "a"},
+	}, func(root string) {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			AssertDirMatches(t, root, DirTree{
+				"a":    {Content: "x", Mode: 0644},
+				"b":    {Mode: fs.ModeDir},
+				"link": {LinkTarget: "b"},
+			})
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "directory "+root+" did not have the expected contents:\n"+
+			"a: expected mode -rw-r--r--, but was -rw-------\n"+
+			"b: expected a directory, but found a file\n"+
+			`link: expected link to "b", but was link to "a"`,
+			result.Failures[0].Message)
+	})
+}
+
+func TestAssertDirMatchesNonexistentDirectory(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertDirMatches(t, "/this/does/not/exist", DirTree{})
+	})
+	assert.True(t, result.Failed)
+}
+
+func TestAssertDirTreeMatches(t *testing.T) {
+	actual := DirTree{"a": {Content: "x", Mode: 0644}, "sub/c": {Content: "z"}, "empty": {Mode: fs.ModeDir | 0755}}
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertDirTreeMatches(t, actual, DirTree{"a": {Content: "x"}, "sub/c": {Content: "z"}, "empty": {Mode: fs.ModeDir}})
+	})
+	assert.False(t, result.Failed)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertDirTreeMatches(t, actual, DirTreeOf(map[string]string{"a": "y", "b": "y", "sub/c": "z"}))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "directory tree did not have the expected contents:\n"+
+		"a: content differs\n--- expected\n+++ actual\n@@ -1,1 +1,1 @@\n-y (no newline at end)\n+x (no newline at end)\n"+
+		"missing: b\nunexpected: empty",
+		result.Failures[0].Message)
+}
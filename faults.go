@@ -0,0 +1,269 @@
+package helpers
+
+import (
+	"io"
+	"math/rand"
+	"time"
+)
+
+// ReaderFault is a transformation that wraps an io.Reader to make it misbehave in some way. Use
+// FaultyReader to apply one or more of these.
+type ReaderFault func(io.Reader) io.Reader
+
+// WriterFault is a transformation that wraps an io.Writer to make it misbehave in some way. Use
+// FaultyWriter to apply one or more of these.
+type WriterFault func(io.Writer) io.Writer
+
+// CloserFault is a transformation that wraps an io.Closer to make it misbehave in some way. Use
+// FaultyCloser or FaultyReadCloser to apply one or more of these.
+type CloserFault func(io.Closer) io.Closer
+
+// FaultyReader wraps an io.Reader with any number of faults. The faults are applied in the order
+// given, so the first one is closest to the original reader.
+//
+//	r := helpers.FaultyReader(strings.NewReader(data),
+//	    helpers.ReadShort(3),                       // return at most 3 bytes per Read
+//	    helpers.ReadFailAfter(10, errors.New("x"))) // then fail after 10 bytes in total
+func FaultyReader(r io.Reader, faults ...ReaderFault) io.Reader {
+	for _, f := range faults {
+		r = f(r)
+	}
+	return r
+}
+
+// FaultyWriter wraps an io.Writer with any number of faults. The faults are applied in the order
+// given, so the first one is closest to the original writer.
+func FaultyWriter(w io.Writer, faults ...WriterFault) io.Writer {
+	for _, f := range faults {
+		w = f(w)
+	}
+	return w
+}
+
+// FaultyCloser wraps an io.Closer with any number of faults. The faults are applied in the order
+// given, so the first one is closest to the original closer.
+func FaultyCloser(c io.Closer, faults ...CloserFault) io.Closer {
+	for _, f := range faults {
+		c = f(c)
+	}
+	return c
+}
+
+// FaultyReadCloser applies faults separately to the Read and Close behavior of an io.ReadCloser.
+// Either list of faults can be empty.
+func FaultyReadCloser(rc io.ReadCloser, readFaults []ReaderFault, closeFaults ...CloserFault) io.ReadCloser {
+	return struct {
+		io.Reader
+		io.Closer
+	}{FaultyReader(rc, readFaults...), FaultyCloser(rc, closeFaults...)}
+}
+
+// ReadFailAfter returns a ReaderFault that allows n bytes to be read, and then returns the
+// specified error from every subsequent Read. It panics if n is negative.
+func ReadFailAfter(n int, err error) ReaderFault {
+	if n < 0 {
+		panic("helpers.ReadFailAfter: n must not be negative")
+	}
+	return func(r io.Reader) io.Reader {
+		return &failAfterReader{reader: r, remaining: n, err: err}
+	}
+}
+
+// ReadTruncateAfter returns a ReaderFault that allows n bytes to be read, and then returns
+// io.ErrUnexpectedEOF, as if a stream had been cut off in the middle. It panics if n is negative.
+func ReadTruncateAfter(n int) ReaderFault {
+	if n < 0 {
+		panic("helpers.ReadTruncateAfter: n must not be negative")
+	}
+	return ReadFailAfter(n, io.ErrUnexpectedEOF)
+}
+
+// ReadShort returns a ReaderFault that causes each Read to return at most the specified number of
+// bytes, regardless of how much data was requested. It panics if maxBytesPerRead is not positive,
+// since a reader that always returns zero bytes would cause most callers to loop forever.
+func ReadShort(maxBytesPerRead int) ReaderFault {
+	if maxBytesPerRead <= 0 {
+		panic("helpers.ReadShort: maxBytesPerRead must be positive")
+	}
+	return func(r io.Reader) io.Reader {
+		return shortReader{reader: r, size: func() int { return maxBytesPerRead }}
+	}
+}
+
+// ReadShortRandom returns a ReaderFault that causes each Read to return a random number of bytes,
+// between 1 and maxBytesPerRead, regardless of how much data was requested. The sizes are taken
+// from the specified random source, so a test can reproduce them by using the same seed. It panics
+// if maxBytesPerRead is not positive.
+func ReadShortRandom(maxBytesPerRead int, source *rand.Rand) ReaderFault {
+	if maxBytesPerRead <= 0 {
+		panic("helpers.ReadShortRandom: maxBytesPerRead must be positive")
+	}
+	return func(r io.Reader) io.Reader {
+		return shortReader{reader: r, size: func() int { return 1 + source.Intn(maxBytesPerRead) }}
+	}
+}
+
+// ReadLatency returns a ReaderFault that waits for the specified duration before each Read.
+func ReadLatency(delay time.Duration) ReaderFault {
+	return func(r io.Reader) io.Reader {
+		return readerFunc(func(p []byte) (int, error) {
+			time.Sleep(delay)
+			return r.Read(p)
+		})
+	}
+}
+
+// ReadThrottle returns a ReaderFault that limits the rate at which data can be read to
+// approximately the specified number of bytes per second. It panics if bytesPerSecond is not
+// positive.
+func ReadThrottle(bytesPerSecond int) ReaderFault {
+	if bytesPerSecond <= 0 {
+		panic("helpers.ReadThrottle: bytesPerSecond must be positive")
+	}
+	return func(r io.Reader) io.Reader {
+		t := newThrottle(bytesPerSecond)
+		return readerFunc(func(p []byte) (int, error) {
+			n, err := r.Read(p[:min(len(p), t.chunkSize)])
+			t.wait(n)
+			return n, err
+		})
+	}
+}
+
+// WriteFailAfter returns a WriterFault that allows n bytes to be written, and then returns the
+// specified error from every subsequent Write. A Write that crosses the limit writes as much as it
+// can before returning the error. It panics if n is negative.
+func WriteFailAfter(n int, err error) WriterFault {
+	if n < 0 {
+		panic("helpers.WriteFailAfter: n must not be negative")
+	}
+	return func(w io.Writer) io.Writer {
+		remaining := n
+		return writerFunc(func(p []byte) (int, error) {
+			if len(p) <= remaining {
+				written, writeErr := w.Write(p)
+				remaining -= written
+				return written, writeErr
+			}
+			written, writeErr := w.Write(p[:remaining])
+			remaining -= written
+			if writeErr != nil {
+				return written, writeErr
+			}
+			return written, err
+		})
+	}
+}
+
+// WriteLatency returns a WriterFault that waits for the specified duration before each Write.
+func WriteLatency(delay time.Duration) WriterFault {
+	return func(w io.Writer) io.Writer {
+		return writerFunc(func(p []byte) (int, error) {
+			time.Sleep(delay)
+			return w.Write(p)
+		})
+	}
+}
+
+// WriteThrottle returns a WriterFault that limits the rate at which data can be written to
+// approximately the specified number of bytes per second. Large writes are split into smaller
+// ones that are spread out over time. It panics if bytesPerSecond is not positive.
+func WriteThrottle(bytesPerSecond int) WriterFault {
+	if bytesPerSecond <= 0 {
+		panic("helpers.WriteThrottle: bytesPerSecond must be positive")
+	}
+	return func(w io.Writer) io.Writer {
+		t := newThrottle(bytesPerSecond)
+		return writerFunc(func(p []byte) (int, error) {
+			total := 0
+			for total < len(p) {
+				n, err := w.Write(p[total:min(len(p), total+t.chunkSize)])
+				total += n
+				t.wait(n)
+				if err != nil {
+					return total, err
+				}
+			}
+			return total, nil
+		})
+	}
+}
+
+// CloseFails returns a CloserFault that calls the original Close method but then returns the
+// specified error.
+func CloseFails(err error) CloserFault {
+	return func(c io.Closer) io.Closer {
+		return closerFunc(func() error {
+			_ = c.Close()
+			return err
+		})
+	}
+}
+
+// CloseHangs returns a CloserFault that causes Close to block until the release channel is closed,
+// and then calls the original Close method.
+func CloseHangs(release <-chan struct{}) CloserFault {
+	return func(c io.Closer) io.Closer {
+		return closerFunc(func() error {
+			<-release
+			return c.Close()
+		})
+	}
+}
+
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+type failAfterReader struct {
+	reader    io.Reader
+	remaining int
+	err       error
+}
+
+func (r *failAfterReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, r.err
+	}
+	n, err := r.reader.Read(p[:min(len(p), r.remaining)])
+	r.remaining -= n
+	return n, err
+}
+
+type shortReader struct {
+	reader io.Reader
+	size   func() int
+}
+
+func (r shortReader) Read(p []byte) (int, error) {
+	return r.reader.Read(p[:min(len(p), r.size())])
+}
+
+type throttle struct {
+	bytesPerSecond int
+	chunkSize      int
+	startTime      time.Time
+	total          int
+}
+
+func newThrottle(bytesPerSecond int) *throttle {
+	// Transferring data in chunks of 1/10 of the per-second rate keeps the rate reasonably smooth
+	return &throttle{bytesPerSecond: bytesPerSecond, chunkSize: max(1, bytesPerSecond/10)}
+}
+
+func (t *throttle) wait(n int) {
+	if t.startTime.IsZero() {
+		t.startTime = time.Now()
+	}
+	t.total += n
+	due := t.startTime.Add(time.Duration(t.total) * time.Second / time.Duration(t.bytesPerSecond))
+	time.Sleep(time.Until(due))
+}
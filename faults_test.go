@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readChunks(t *testing.T, r io.Reader, bufSize int) ([]string, error) {
+	var chunks []string
+	buf := make([]byte, bufSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			chunks = append(chunks, string(buf[:n]))
+		}
+		if err != nil {
+			return chunks, err
+		}
+		require.Less(t, len(chunks), 1000)
+	}
+}
+
+func TestReadFailAfter(t *testing.T) {
+	myErr := errors.New("sorry")
+	r := FaultyReader(strings.NewReader("abcdefg"), ReadFailAfter(5, myErr))
+	chunks, err := readChunks(t, r, 100)
+	assert.Equal(t, []string{"abcde"}, chunks)
+	assert.Equal(t, myErr, err)
+
+	r = FaultyReader(strings.NewReader("abc"), ReadFailAfter(5, myErr))
+	chunks, err = readChunks(t, r, 100)
+	assert.Equal(t, []string{"abc"}, chunks)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadTruncateAfter(t *testing.T) {
+	r := FaultyReader(strings.NewReader("abcdefg"), ReadTruncateAfter(3))
+	data, err := io.ReadAll(r)
+	assert.Equal(t, "abc", string(data))
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestReadShort(t *testing.T) {
+	r := FaultyReader(strings.NewReader("abcdefg"), ReadShort(3))
+	chunks, err := readChunks(t, r, 100)
+	assert.Equal(t, []string{"abc", "def", "g"}, chunks)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadShortRandom(t *testing.T) {
+	data := strings.Repeat("x", 100)
+	r1 := FaultyReader(strings.NewReader(data), ReadShortRandom(10, rand.New(rand.NewSource(1))))
+	chunks1, _ := readChunks(t, r1, 100)
+	r2 := FaultyReader(strings.NewReader(data), ReadShortRandom(10, rand.New(rand.NewSource(1))))
+	chunks2, _ := readChunks(t, r2, 100)
+
+	assert.Equal(t, chunks1, chunks2) // same seed produces the same sizes
+	assert.Equal(t, data, strings.Join(chunks1, ""))
+	for _, c := range chunks1 {
+		assert.LessOrEqual(t, len(c), 10)
+	}
+}
+
+func TestReadLatency(t *testing.T) {
+	r := FaultyReader(strings.NewReader("abc"), ReadLatency(time.Millisecond*20))
+	start := time.Now()
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", string(data))
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*40)) // at least 2 reads
+}
+
+func TestReadThrottle(t *testing.T) {
+	r := FaultyReader(strings.NewReader(strings.Repeat("x", 100)), ReadThrottle(1000))
+	start := time.Now()
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Len(t, data, 100)
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*90))
+}
+
+func TestComposedReaderFaults(t *testing.T) {
+	myErr := errors.New("sorry")
+	r := FaultyReader(strings.NewReader("abcdefg"), ReadShort(2), ReadFailAfter(5, myErr))
+	chunks, err := readChunks(t, r, 100)
+	assert.Equal(t, []string{"ab", "cd", "e"}, chunks)
+	assert.Equal(t, myErr, err)
+}
+
+func TestWriteFailAfter(t *testing.T) {
+	myErr := errors.New("sorry")
+	var buf bytes.Buffer
+	w := FaultyWriter(&buf, WriteFailAfter(5, myErr))
+	n, err := w.Write([]byte("abc"))
+	assert.Equal(t, 3, n)
+	assert.NoError(t, err)
+	n, err = w.Write([]byte("defg"))
+	assert.Equal(t, 2, n)
+	assert.Equal(t, myErr, err)
+	n, err = w.Write([]byte("h"))
+	assert.Equal(t, 0, n)
+	assert.Equal(t, myErr, err)
+	assert.Equal(t, "abcde", buf.String())
+}
+
+func TestWriteLatencyAndThrottle(t *testing.T) {
+	var buf bytes.Buffer
+	w := FaultyWriter(&buf, WriteLatency(time.Millisecond*20), WriteThrottle(1000))
+	start := time.Now()
+	n, err := w.Write([]byte(strings.Repeat("x", 100)))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, n)
+	assert.Equal(t, 100, buf.Len())
+	assert.GreaterOrEqual(t, int64(time.Since(start)), int64(time.Millisecond*110))
+}
+
+func TestCloseFails(t *testing.T) {
+	myErr := errors.New("sorry")
+	c := &myCloser{}
+	err := FaultyCloser(c, CloseFails(myErr)).Close()
+	assert.Equal(t, myErr, err)
+	assert.True(t, c.closed)
+}
+
+func TestCloseHangs(t *testing.T) {
+	release := make(chan struct{})
+	rc := FaultyReadCloser(io.NopCloser(strings.NewReader("abc")), []ReaderFault{ReadShort(1)}, CloseHangs(release))
+
+	chunks, _ := readChunks(t, rc, 100)
+	assert.Equal(t, []string{"a", "b", "c"}, chunks)
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- rc.Close()
+	}()
+	AssertNoMoreValues(t, closed, time.Millisecond*20)
+	close(release)
+	assert.NoError(t, RequireValue(t, closed, time.Second))
+}
+
+func TestFaultsPanicForInvalidArguments(t *testing.T) {
+	assert.PanicsWithValue(t, "helpers.ReadFailAfter: n must not be negative",
+		func() { ReadFailAfter(-1, errors.New("x")) })
+	assert.PanicsWithValue(t, "helpers.ReadTruncateAfter: n must not be negative", func() { ReadTruncateAfter(-1) })
+	assert.PanicsWithValue(t, "helpers.ReadShort: maxBytesPerRead must be positive", func() { ReadShort(0) })
+	assert.PanicsWithValue(t, "helpers.ReadShortRandom: maxBytesPerRead must be positive",
+		func() { ReadShortRandom(0, rand.New(rand.NewSource(0))) })
+	assert.PanicsWithValue(t, "helpers.ReadThrottle: bytesPerSecond must be positive", func() { ReadThrottle(0) })
+	assert.PanicsWithValue(t, "helpers.WriteFailAfter: n must not be negative",
+		func() { WriteFailAfter(-1, errors.New("x")) })
+	assert.PanicsWithValue(t, "helpers.WriteThrottle: bytesPerSecond must be positive", func() { WriteThrottle(-1) })
+}
@@ -2,61 +2,326 @@ package helpers
 
 import (
 	"fmt"
-	"log"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/require"
 )
 
+const fileStatePollInterval = time.Millisecond * 10
+
+// FileState describes the state of a file at some moment, as returned by ReadFileState. It is used
+// with RequireFileEventually and matchers such as FileExists and FileContent.
+type FileState struct {
+	// Path is the path of the file.
+	Path string
+	// Exists is true if the file or directory exists.
+	Exists bool
+	// Content is the content of the file, if it exists and is not a directory.
+	Content string
+	// Mode is the file mode, if it exists.
+	Mode fs.FileMode
+	// ModTime is the modification time, if it exists.
+	ModTime time.Time
+	// Err is any error other than the file not existing that prevented the state from being read.
+	Err error
+}
+
 // FilePathExists is simply a shortcut for using os.Stat to check for a file's or directory's existence.
 func FilePathExists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
 
-// WithTempFile creates a temporary file, passes its name to the given function, then ensures that the file is deleted.
+// String returns a brief description of the state, such as `file.txt exists with content "abc"`.
+func (s FileState) String() string {
+	switch {
+	case s.Err != nil:
+		return fmt.Sprintf("%s could not be read: %s", s.Path, s.Err)
+	case !s.Exists:
+		return fmt.Sprintf("%s does not exist", s.Path)
+	case s.Mode.IsDir():
+		return fmt.Sprintf("%s exists and is a directory", s.Path)
+	default:
+		return fmt.Sprintf("%s exists with content %q", s.Path, s.Content)
+	}
+}
+
+// ReadFileState returns the current state of a file. Errors are reported in FileState.Err rather
+// than returned, so that they can be tested with a matcher like any other state.
+func ReadFileState(path string) FileState {
+	if !FilePathExists(path) {
+		return FileState{Path: path}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileState{Path: path, Err: err}
+	}
+	state := FileState{Path: path, Exists: true, Mode: info.Mode(), ModTime: info.ModTime()}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			state.Err = err
+		}
+		state.Content = string(data)
+	}
+	return state
+}
+
+// FileExists is a matcher for a FileState that tests whether the file exists.
+func FileExists() matchers.Matcher {
+	return fileStateExists().Should(matchers.Equal(true))
+}
+
+// FileDoesNotExist is a matcher for a FileState that tests whether the file does not exist.
+func FileDoesNotExist() matchers.Matcher {
+	return fileStateExists().Should(matchers.Equal(false))
+}
+
+// FileContent is a matcher for a FileState that tests whether the file exists and its content,
+// as a string, matches the matcher.
 //
-// If for any reason it is not possible to create the file, a panic is raised since the test code cannot continue.
+//	helpers.RequireFileEventually(t, path, helpers.FileContent(matchers.JSONStrEqual(`{"a": 2}`)), time.Second)
+func FileContent(matcher matchers.Matcher) matchers.Matcher {
+	return matchers.Transform("content", func(value any) (any, error) {
+		state := value.(FileState)
+		if !state.Exists || state.Mode.IsDir() {
+			return nil, fmt.Errorf("%s", state)
+		}
+		return state.Content, nil
+	}).EnsureInputValueType(FileState{}).Should(matcher)
+}
+
+// FileModTime is a matcher for a FileState that tests whether the file exists and its
+// modification time matches the matcher.
+func FileModTime(matcher matchers.Matcher) matchers.Matcher {
+	return matchers.Transform("modification time", func(value any) (any, error) {
+		state := value.(FileState)
+		if !state.Exists {
+			return nil, fmt.Errorf("%s", state)
+		}
+		return state.ModTime, nil
+	}).EnsureInputValueType(FileState{}).Should(matcher)
+}
+
+func fileStateExists() matchers.MatcherTransform {
+	return matchers.Transform("file exists", func(value any) (any, error) {
+		return value.(FileState).Exists, nil
+	}).EnsureInputValueType(FileState{})
+}
+
+// RequireFileEventually repeatedly checks the state of a file until it matches the matcher, and
+// returns that state. If that does not happen before the timeout, the test fails and stops
+// immediately, describing the last state that was observed. The matcher can be FileExists,
+// FileDoesNotExist, FileContent, FileModTime, or any other matcher for a FileState.
 //
-// If deletion of the file fails (assuming it has not already been deleted) then an error is logged, but there is no
-// panic.
+//	helpers.RewriteFileAtomically(inputPath, newData)
+//	helpers.RequireFileEventually(t, outputPath, helpers.FileContent(matchers.StringContains("done")), time.Second)
 //
-//	helpers.WithTempFile(func(path string) {
-//		DoSomethingWithTempFile(path)
-//	}) // the file is deleted at the end of this block
-func WithTempFile(f func(filePath string)) {
+// As with RequireValue, the timeout is scaled by ScaledTimeout, and FailureOptionDumpGoroutines
+// can be passed as a custom message argument.
+func RequireFileEventually(
+	t require.TestingT,
+	path string,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) FileState {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.NewTimer(ScaledTimeout(timeout))
+	defer deadline.Stop()
+	ticker := time.NewTicker(fileStatePollInterval)
+	defer ticker.Stop()
+	for {
+		state := ReadFileState(path)
+		pass, desc := matcher.Test(state)
+		if pass {
+			return state
+		}
+		select {
+		case <-deadline.C:
+			failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+				"expected file %s to match within %s, but it did not: %s", path, describeTimeout(timeout), desc)
+			t.FailNow()
+			return state
+		case <-ticker.C:
+		}
+	}
+}
+
+// RewriteFileAtomically replaces the content of a file by writing the data to a temporary file in
+// the same directory and then renaming it, so that anything reading the file sees either the old
+// content or the new content, never a partly written file. This is how many programs update
+// configuration files, so it is a realistic way to test code that watches a file. The file does
+// not need to exist already; if it does, its permissions are kept.
+//
+// Some file watchers detect changes by modification time, which on some file systems has a
+// granularity as coarse as one second; to make sure the change is seen, use TouchWithMtime
+// afterward.
+func RewriteFileAtomically(path string, data []byte) error {
+	perm := fs.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	_, err = temp.Write(data)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+	}
+	return err
+}
+
+// TouchWithMtime sets the modification time (and access time) of a file, creating an empty file
+// if it does not exist. Setting an explicit time, rather than the current time, makes tests of
+// change detection independent of the file system's timestamp granularity.
+//
+//	_ = helpers.RewriteFileAtomically(path, newData)
+//	_ = helpers.TouchWithMtime(path, time.Now().Add(time.Hour))
+func TouchWithMtime(path string, mtime time.Time) error {
+	if !FilePathExists(path) {
+		if err := os.WriteFile(path, nil, 0644); err != nil { //nolint:gosec // test files don't need restricted permissions
+			return err
+		}
+	}
+	return os.Chtimes(path, mtime, mtime)
+}
+
+// TempFile creates a temporary file and returns its path. The file is deleted when the test
+// completes, if it has not already been deleted.
+//
+// If it is not possible to create the file, the test fails and stops immediately. If deleting the
+// file fails, the test fails.
+//
+//	path := helpers.TempFile(t)
+//	DoSomethingWithTempFile(path)
+func TempFile(t CleanupT) string {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
 	file, err := os.CreateTemp("", "test")
 	if err != nil {
-		panic(fmt.Errorf("can't create temp file: %s", err))
+		t.Errorf("can't create temp file: %s", err)
+		t.FailNow()
+		return ""
 	}
 	_ = file.Close()
 	path := file.Name()
-	defer (func() {
+	t.Cleanup(func() {
 		if FilePathExists(path) {
-			err := os.Remove(path)
-			if err != nil {
-				log.Printf("Could not delete temp file %s: %s", path, err)
+			if err := os.Remove(path); err != nil {
+				t.Errorf("could not delete temp file %s: %s", path, err)
 			}
 		}
-	})()
-	f(file.Name())
+	})
+	return path
+}
+
+// TempFileData is identical to TempFile except that it prepopulates the file with the specified
+// data.
+func TempFileData(t CleanupT, data []byte) string {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	path := TempFile(t)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Errorf("can't write to temp file: %s", err)
+		t.FailNow()
+	}
+	return path
+}
+
+// TempDir creates a temporary directory and returns its path. The directory and its contents are
+// deleted when the test completes.
+//
+// If it is not possible to create the directory, the test fails and stops immediately. If deleting
+// the directory fails, the test fails.
+//
+// This is similar to testing.T.TempDir, but can be used with any CleanupT, such as a
+// testbox.CleanupT.
+func TempDir(t CleanupT) string {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	path, err := os.MkdirTemp("", "test")
+	if err != nil {
+		t.Errorf("can't create temp directory: %s", err)
+		t.FailNow()
+		return ""
+	}
+	t.Cleanup(func() {
+		if err := removeAllWritable(path); err != nil {
+			t.Errorf("could not delete temp directory %s: %s", path, err)
+		}
+	})
+	return path
+}
+
+// removeAllWritable is like os.RemoveAll, except that if that fails, it makes every directory
+// writable and tries again; the test may have created read-only directories, for instance with
+// TempDirTree.
+func removeAllWritable(path string) error {
+	if os.RemoveAll(path) == nil {
+		return nil
+	}
+	_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(p, 0700)
+		}
+		return nil
+	})
+	return os.RemoveAll(path)
+}
+
+// WithTempFile creates a temporary file, passes its name to the given function, then ensures that the file is deleted.
+//
+// If for any reason it is not possible to create the file, a panic is raised since the test code cannot continue.
+//
+// If deletion of the file fails (assuming it has not already been deleted) then an error is logged, but there is no
+// panic.
+//
+//	helpers.WithTempFile(func(path string) {
+//		DoSomethingWithTempFile(path)
+//	}) // the file is deleted at the end of this block
+//
+// To delete the file at the end of the test instead, use TempFile.
+func WithTempFile(f func(filePath string)) {
+	WithCleanupScope(func(t CleanupT) {
+		f(TempFile(t))
+	})
 }
 
 // WithTempFileData is identical to WithTempFile except that it prepopulates the file with the
 // specified data.
 func WithTempFileData(data []byte, f func(filePath string)) {
-	WithTempFile(func(filePath string) {
-		if err := os.WriteFile(filePath, data, 0600); err != nil {
-			panic(fmt.Errorf("can't write to temp file: %s", err))
-		}
-		f(filePath)
+	WithCleanupScope(func(t CleanupT) {
+		f(TempFileData(t, data))
 	})
 }
 
 // WithTempDir creates a temporary directory, calls the function with its path, then removes it.
+//
+// To remove the directory at the end of the test instead, use TempDir.
 func WithTempDir(f func(path string)) {
-	path, err := os.MkdirTemp("", "test")
-	if err != nil {
-		panic(err)
-	}
-	defer func() { _ = os.RemoveAll(path) }()
-	f(path)
+	WithCleanupScope(func(t CleanupT) {
+		f(TempDir(t))
+	})
 }
@@ -1,9 +1,14 @@
 package helpers
 
 import (
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -40,3 +45,167 @@ func TestWithTempDir(t *testing.T) {
 	})
 	assert.False(t, FilePathExists(path))
 }
+
+func TestTempFile(t *testing.T) {
+	var filePath string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		filePath = TempFile(t)
+		assert.True(t, FilePathExists(filePath))
+	})
+	assert.False(t, result.Failed)
+	assert.False(t, FilePathExists(filePath))
+}
+
+func TestTempFileDoesNotFailIfFileWasAlreadyDeleted(t *testing.T) {
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		require.NoError(t, os.Remove(TempFile(t)))
+	})
+	assert.False(t, result.Failed)
+}
+
+func TestTempFileData(t *testing.T) {
+	var filePath string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		filePath = TempFileData(t, []byte("hello"))
+		data, err := os.ReadFile(filePath)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+	assert.False(t, result.Failed)
+	assert.False(t, FilePathExists(filePath))
+}
+
+func TestTempDir(t *testing.T) {
+	var path string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		path = TempDir(t)
+		assert.NoError(t, os.WriteFile(filepath.Join(path, "x"), []byte("hello"), 0600))
+	})
+	assert.False(t, result.Failed)
+	assert.False(t, FilePathExists(path))
+}
+
+func TestReadFileState(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+
+	state := ReadFileState(path)
+	assert.Equal(t, FileState{Path: path}, state)
+	assert.Equal(t, path+" does not exist", state.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0600))
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+	state = ReadFileState(path)
+	assert.True(t, state.Exists)
+	assert.Equal(t, "hello", state.Content)
+	assert.Equal(t, fs.FileMode(0600), state.Mode)
+	assert.True(t, modTime.Equal(state.ModTime))
+	assert.NoError(t, state.Err)
+	assert.Equal(t, path+` exists with content "hello"`, state.String())
+
+	state = ReadFileState(dir)
+	assert.True(t, state.Exists)
+	assert.True(t, state.Mode.IsDir())
+	assert.Equal(t, dir+" exists and is a directory", state.String())
+}
+
+func TestFileStateMatchers(t *testing.T) {
+	missing := FileState{Path: "x"}
+	file := FileState{Path: "x", Exists: true, Content: "hello", ModTime: time.Unix(1000, 0)}
+
+	assertMatcherPasses(t, FileExists(), file)
+	assertMatcherFails(t, FileExists(), missing, "file exists did not equal true")
+	assertMatcherPasses(t, FileDoesNotExist(), missing)
+	assertMatcherFails(t, FileDoesNotExist(), file, "file exists did not equal false")
+
+	assertMatcherPasses(t, FileContent(matchers.StringContains("ell")), file)
+	assertMatcherFails(t, FileContent(matchers.Equal("bye")), file, `content did not equal "bye"`)
+	assertMatcherFails(t, FileContent(matchers.Equal("bye")), missing, "x does not exist")
+
+	assertMatcherPasses(t, FileModTime(matchers.Equal(time.Unix(1000, 0))), file)
+	assertMatcherFails(t, FileModTime(matchers.Equal(time.Unix(1000, 0))), missing, "x does not exist")
+}
+
+func assertMatcherPasses(t *testing.T, m matchers.Matcher, value any) {
+	pass, desc := m.Test(value)
+	assert.True(t, pass, desc)
+}
+
+func assertMatcherFails(t *testing.T, m matchers.Matcher, value any, descSubstring string) {
+	pass, desc := m.Test(value)
+	assert.False(t, pass)
+	assert.Contains(t, desc, descSubstring)
+}
+
+func TestRequireFileEventually(t *testing.T) {
+	withTimeoutScale(t, 1)
+	path := filepath.Join(t.TempDir(), "file.txt")
+
+	t.Run("becomes true", func(t *testing.T) {
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			_ = RewriteFileAtomically(path, []byte("hello"))
+		}()
+		state := RequireFileEventually(t, path, FileContent(matchers.Equal("hello")), time.Second)
+		assert.Equal(t, "hello", state.Content)
+
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			_ = os.Remove(path)
+		}()
+		state = RequireFileEventually(t, path, FileDoesNotExist(), time.Second)
+		assert.False(t, state.Exists)
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			RequireFileEventually(t, path, FileExists(), time.Millisecond*50, "custom %s", "message")
+			assert.Fail(t, "should not have continued")
+		})
+		require.Len(t, result.Failures, 2)
+		assert.Equal(t, "expected file "+path+" to match within 50ms, but it did not: "+
+			"file exists did not equal true\nfull value was: "+path+" does not exist", result.Failures[0].Message)
+		assert.Equal(t, "custom message", result.Failures[1].Message)
+	})
+}
+
+func TestRewriteFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+
+	require.NoError(t, RewriteFileAtomically(path, []byte("first")))
+	state := ReadFileState(path)
+	assert.Equal(t, "first", state.Content)
+	assert.Equal(t, fs.FileMode(0644), state.Mode)
+
+	require.NoError(t, os.Chmod(path, 0600))
+	require.NoError(t, RewriteFileAtomically(path, []byte("second")))
+	state = ReadFileState(path)
+	assert.Equal(t, "second", state.Content)
+	assert.Equal(t, fs.FileMode(0600), state.Mode)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1) // no temporary files left behind
+
+	assert.Error(t, RewriteFileAtomically(filepath.Join(dir, "nonexistent", "x"), nil))
+}
+
+func TestTouchWithMtime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, TouchWithMtime(path, mtime))
+	state := ReadFileState(path)
+	assert.True(t, state.Exists)
+	assert.Equal(t, "", state.Content)
+	assert.True(t, mtime.Equal(state.ModTime))
+
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0600))
+	later := mtime.Add(time.Hour)
+	require.NoError(t, TouchWithMtime(path, later))
+	state = ReadFileState(path)
+	assert.Equal(t, "data", state.Content)
+	assert.True(t, later.Equal(state.ModTime))
+}
@@ -0,0 +1,303 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/launchdarkly/go-test-helpers/v3/jsonhelpers"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// GoldenUpdateEnvVar is the name of an environment variable that, if set to any non-empty value
+	// other than "false" or "0", causes golden files to be rewritten instead of compared. See
+	// GoldenFiles.
+	GoldenUpdateEnvVar = "GO_TEST_HELPERS_UPDATE_GOLDEN"
+
+	// GoldenUpdateFlagName is the name of a boolean command-line flag that, if the test binary
+	// defines it and it is set, causes golden files to be rewritten instead of compared. See
+	// GoldenFiles.
+	GoldenUpdateFlagName = "update"
+
+	goldenFileSuffix = ".golden"
+	goldenFileDir    = "testdata"
+)
+
+// GoldenFiles manages a directory of golden files: snapshots of expected test output that are
+// stored alongside the tests. Each file is identified by a name, which can contain "/" separators,
+// and is stored as <dir>/<name>.golden.
+//
+// Normally, the assertion methods compare the actual output to the contents of the file. If
+// update mode is enabled, they instead write the actual output to the file and always pass. Update
+// mode is enabled if the environment variable GO_TEST_HELPERS_UPDATE_GOLDEN is set, or if the test
+// binary defines a boolean "-update" flag and it is set:
+//
+//	var _ = flag.Bool("update", false, "rewrite golden files")
+//
+//	// go test ./... -update
+//	// or: GO_TEST_HELPERS_UPDATE_GOLDEN=1 go test ./...
+//
+// Since this package cannot know which tests will run, it does not define the flag itself.
+//
+// A GoldenFiles instance remembers which files it has been asked about, so it can report files
+// that no test uses any more; see Obsolete and AssertNoObsolete. To use this, share a single
+// instance among all of a package's tests. Its methods are safe for concurrent use.
+//
+// If you do not need obsolete file detection, you can use the simpler functions AssertGolden,
+// AssertGoldenJSON, and AssertGoldenValue, which use the "testdata" directory.
+type GoldenFiles struct {
+	dir    string
+	update bool
+	used   map[string]struct{}
+	lock   sync.Mutex
+}
+
+// NewGoldenFiles creates a GoldenFiles instance for the specified directory, which is normally
+// "testdata" or a subdirectory of it. Update mode is determined at the time this is called.
+func NewGoldenFiles(dir string) *GoldenFiles {
+	return &GoldenFiles{dir: dir, update: isGoldenUpdateMode(), used: make(map[string]struct{})}
+}
+
+// Dir returns the directory that contains the golden files.
+func (g *GoldenFiles) Dir() string {
+	return g.dir
+}
+
+// Path returns the full path of the golden file with the specified name.
+func (g *GoldenFiles) Path(name string) string {
+	return filepath.Join(g.dir, filepath.FromSlash(name)+goldenFileSuffix)
+}
+
+// Assert compares raw output to the golden file with the specified name. The bytes must match
+// exactly; on failure, the differences are shown as a line-by-line diff.
+//
+//	golden := helpers.NewGoldenFiles("testdata")
+//	golden.Assert(t, "report-output", output)
+func (g *GoldenFiles) Assert(t assert.TestingT, name string, actual []byte, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return g.check(t, name, actual, customMessageAndArgs, func(expected []byte) string {
+		if bytes.Equal(expected, actual) {
+			return ""
+		}
+		return describeLineDiff(g.Path(name), "actual", string(expected), string(actual))
+	})
+}
+
+// AssertJSON compares JSON output to the golden file with the specified name. Both values are
+// parsed, so differences in formatting and property order are ignored, and the differences are
+// described with jsonhelpers.JSONDiff. In update mode, the file is written in canonical form with
+// alphabetized properties (see jsonhelpers.CanonicalizeJSON) and indentation.
+//
+// The actual value can be anything that jsonhelpers.JValueOf accepts: []byte or string JSON data,
+// or any value that can be marshaled to JSON.
+func (g *GoldenFiles) AssertJSON(t assert.TestingT, name string, actual any, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	actualValue := jsonhelpers.JValueOf(actual)
+	if actualValue.Error() != nil {
+		g.markUsed(name)
+		failWithMessageAndArgs(t, customMessageAndArgs, "actual value for golden file %s was not valid JSON: %s",
+			g.Path(name), actualValue.Error())
+		return false
+	}
+	actualJSON := indentGoldenJSON(jsonhelpers.CanonicalizeJSON([]byte(actualValue.String())))
+	return g.check(t, name, actualJSON, customMessageAndArgs, func(expected []byte) string {
+		diff, err := jsonhelpers.JSONDiff(expected, actualJSON)
+		if err != nil {
+			return fmt.Sprintf("golden file is not valid JSON: %s", err)
+		}
+		return strings.Join(diff.Describe("expected", "actual"), "\n")
+	})
+}
+
+// AssertValue compares a Go value to the golden file with the specified name. The value is
+// rendered as text with matchers.DescribeValue, and compared the same way as in Assert.
+func (g *GoldenFiles) AssertValue(t assert.TestingT, name string, actual any, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return g.Assert(t, name, []byte(matchers.DescribeValue(actual)+"\n"), customMessageAndArgs...)
+}
+
+// Obsolete returns the names of all golden files in the directory that have not been used by any
+// of this instance's assertion methods, in alphabetical order. It is meant to be called after all
+// of the tests that use golden files have run.
+func (g *GoldenFiles) Obsolete() ([]string, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	var ret []string
+	err := filepath.WalkDir(g.dir, func(fullPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && fullPath == g.dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(fullPath, goldenFileSuffix) {
+			return nil
+		}
+		relPath, err := filepath.Rel(g.dir, fullPath)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(relPath), goldenFileSuffix)
+		if _, found := g.used[name]; !found {
+			ret = append(ret, name)
+		}
+		return nil
+	})
+	sort.Strings(ret)
+	return ret, err
+}
+
+// AssertNoObsolete asserts that there are no obsolete golden files (see Obsolete). In update
+// mode, it deletes the obsolete files instead.
+//
+// This should be called only after all of the tests that use this GoldenFiles instance have run;
+// for instance, from TestMain, or from a final test at the end of the last test file.
+func (g *GoldenFiles) AssertNoObsolete(t assert.TestingT, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	obsolete, err := g.Obsolete()
+	if err != nil {
+		failWithMessageAndArgs(t, customMessageAndArgs, "could not read golden files in %s: %s", g.dir, err)
+		return false
+	}
+	if len(obsolete) == 0 {
+		return true
+	}
+	if g.update {
+		for _, name := range obsolete {
+			if err := os.Remove(g.Path(name)); err != nil {
+				failWithMessageAndArgs(t, customMessageAndArgs, "could not delete obsolete golden file: %s", err)
+				return false
+			}
+		}
+		return true
+	}
+	paths := make([]string, 0, len(obsolete))
+	for _, name := range obsolete {
+		paths = append(paths, g.Path(name))
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs,
+		"found %d obsolete golden file(s) that were not used by any test; run the tests in update mode to delete them:\n%s",
+		len(obsolete), strings.Join(paths, "\n"))
+	return false
+}
+
+func (g *GoldenFiles) markUsed(name string) {
+	g.lock.Lock()
+	g.used[name] = struct{}{}
+	g.lock.Unlock()
+}
+
+// check does the logic that is common to all of the assertion methods. The compare function
+// returns a description of the differences, or "" if the values are equivalent.
+func (g *GoldenFiles) check(
+	t assert.TestingT,
+	name string,
+	actual []byte,
+	customMessageAndArgs []any,
+	compare func(expected []byte) string,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if !fs.ValidPath(name) {
+		failWithMessageAndArgs(t, customMessageAndArgs, "invalid golden file name: %q", name)
+		return false
+	}
+	g.markUsed(name)
+	filePath := g.Path(name)
+	if g.update {
+		if err := os.MkdirAll(filepath.Dir(filePath), defaultDirTreeDirMode); err != nil {
+			failWithMessageAndArgs(t, customMessageAndArgs, "could not create directory for golden file: %s", err)
+			return false
+		}
+		if err := os.WriteFile(filePath, actual, defaultDirTreeFileMode); err != nil {
+			failWithMessageAndArgs(t, customMessageAndArgs, "could not write golden file: %s", err)
+			return false
+		}
+		return true
+	}
+	expected, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"golden file %s does not exist; run the tests with -%s or %s=1 to create it",
+				filePath, GoldenUpdateFlagName, GoldenUpdateEnvVar)
+		} else {
+			failWithMessageAndArgs(t, customMessageAndArgs, "could not read golden file: %s", err)
+		}
+		return false
+	}
+	if desc := compare(expected); desc != "" {
+		failWithMessageAndArgs(t, customMessageAndArgs, "output did not match golden file %s:\n%s", filePath, desc)
+		return false
+	}
+	return true
+}
+
+// AssertGolden is a shortcut for calling GoldenFiles.Assert on the "testdata" directory.
+//
+//	helpers.AssertGolden(t, t.Name(), output) // compares output to testdata/TestName.golden
+func AssertGolden(t assert.TestingT, name string, actual []byte, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return NewGoldenFiles(goldenFileDir).Assert(t, name, actual, customMessageAndArgs...)
+}
+
+// AssertGoldenJSON is a shortcut for calling GoldenFiles.AssertJSON on the "testdata" directory.
+func AssertGoldenJSON(t assert.TestingT, name string, actual any, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return NewGoldenFiles(goldenFileDir).AssertJSON(t, name, actual, customMessageAndArgs...)
+}
+
+// AssertGoldenValue is a shortcut for calling GoldenFiles.AssertValue on the "testdata" directory.
+func AssertGoldenValue(t assert.TestingT, name string, actual any, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return NewGoldenFiles(goldenFileDir).AssertValue(t, name, actual, customMessageAndArgs...)
+}
+
+func isGoldenUpdateMode() bool {
+	if value := os.Getenv(GoldenUpdateEnvVar); value != "" && value != "false" && value != "0" {
+		return true
+	}
+	if f := flag.Lookup(GoldenUpdateFlagName); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if value, ok := getter.Get().(bool); ok {
+				return value
+			}
+		}
+	}
+	return false
+}
+
+func indentGoldenJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return data
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
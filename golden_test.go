@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type goldenTestStruct struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestGoldenAssertSuccess(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{
+		"a.golden":     "line1\nline2\n",
+		"sub/b.golden": `{"x": [1, 2], "y": true}`,
+		"c.golden":     `{"count":3,"name":"n"}` + "\n",
+	}), func(dir string) {
+		g := NewGoldenFiles(dir)
+		assert.True(t, g.Assert(t, "a", []byte("line1\nline2\n")))
+		assert.True(t, g.AssertJSON(t, "sub/b", `{"y":true,"x":[1,2]}`))
+		assert.True(t, g.AssertValue(t, "c", goldenTestStruct{Name: "n", Count: 3}))
+	})
+}
+
+func TestGoldenAssertMismatch(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{"a.golden": "line1\nline2\n"}), func(dir string) {
+		g := NewGoldenFiles(dir)
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			g.Assert(t, "a", []byte("line1\nline3\n"))
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		path := filepath.Join(dir, "a.golden")
+		assert.Equal(t, "output did not match golden file "+path+":\n"+
+			"--- "+path+"\n+++ actual\n@@ -1,2 +1,2 @@\n line1\n-line2\n+line3",
+			result.Failures[0].Message)
+	})
+}
+
+func TestGoldenAssertJSONMismatch(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{"a.golden": `{"x": 1, "y": 2}`}), func(dir string) {
+		g := NewGoldenFiles(dir)
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			g.AssertJSON(t, "a", map[string]int{"x": 1, "y": 3})
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "output did not match golden file "+filepath.Join(dir, "a.golden")+":\n"+
+			`at "y": expected = 2, actual = 3`,
+			result.Failures[0].Message)
+
+		result = testbox.SandboxTest(func(t testbox.TestingT) {
+			g.AssertJSON(t, "a", "{not json")
+		})
+		assert.True(t, result.Failed)
+	})
+}
+
+func TestGoldenFileMissing(t *testing.T) {
+	WithTempDir(func(dir string) {
+		g := NewGoldenFiles(dir)
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			g.Assert(t, "a", []byte("x"))
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "golden file "+filepath.Join(dir, "a.golden")+
+			" does not exist; run the tests with -update or GO_TEST_HELPERS_UPDATE_GOLDEN=1 to create it",
+			result.Failures[0].Message)
+	})
+}
+
+func TestGoldenInvalidName(t *testing.T) {
+	WithTempDir(func(dir string) {
+		g := NewGoldenFiles(dir)
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			g.Assert(t, "../a", []byte("x"))
+		})
+		require.True(t, result.Failed)
+		assert.Equal(t, `invalid golden file name: "../a"`, result.Failures[0].Message)
+	})
+}
+
+func TestGoldenUpdateMode(t *testing.T) {
+	t.Setenv(GoldenUpdateEnvVar, "1")
+	WithTempDirTree(DirTreeOf(map[string]string{
+		"a.golden":        "old\n",
+		"obsolete.golden": "whatever",
+		"notgolden.txt":   "whatever",
+	}), func(dir string) {
+		g := NewGoldenFiles(dir)
+		assert.True(t, g.Assert(t, "a", []byte("new\n")))
+		assert.True(t, g.AssertJSON(t, "sub/b", `{"y":true,"x":[1,2]}`))
+		assert.True(t, g.AssertValue(t, "c", []string{"p", "q"}))
+		assert.True(t, g.AssertNoObsolete(t))
+
+		AssertDirMatches(t, dir, DirTreeOf(map[string]string{
+			"a.golden":      "new\n",
+			"sub/b.golden":  "{\n  \"x\": [\n    1,\n    2\n  ],\n  \"y\": true\n}\n",
+			"c.golden":      "[\"p\", \"q\"]\n",
+			"notgolden.txt": "whatever",
+		}))
+	})
+}
+
+func TestGoldenObsolete(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{
+		"a.golden":         "x",
+		"sub/b.golden":     "y",
+		"sub/c.golden":     "z",
+		"sub/notgolden.go": "",
+	}), func(dir string) {
+		g := NewGoldenFiles(dir)
+		g.Assert(t, "sub/b", []byte("y"))
+
+		obsolete, err := g.Obsolete()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "sub/c"}, obsolete)
+
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			g.AssertNoObsolete(t)
+		})
+		require.True(t, result.Failed)
+		require.Len(t, result.Failures, 1)
+		assert.Equal(t, "found 2 obsolete golden file(s) that were not used by any test; "+
+			"run the tests in update mode to delete them:\n"+
+			filepath.Join(dir, "a.golden")+"\n"+filepath.Join(dir, "sub", "c.golden"),
+			result.Failures[0].Message)
+
+		// A failed assertion still counts as using the file
+		testbox.SandboxTest(func(t testbox.TestingT) {
+			g.Assert(t, "a", []byte("wrong"))
+			g.Assert(t, "sub/c", []byte("wrong"))
+		})
+		assert.True(t, g.AssertNoObsolete(t))
+	})
+}
+
+func TestGoldenObsoleteWithNonexistentDirectory(t *testing.T) {
+	obsolete, err := NewGoldenFiles("/this/does/not/exist").Obsolete()
+	assert.NoError(t, err)
+	assert.Len(t, obsolete, 0)
+}
+
+func TestAssertGoldenUsesTestdata(t *testing.T) {
+	WithTempDirTree(DirTreeOf(map[string]string{
+		"testdata/a.golden": "x",
+		"testdata/b.golden": `{"a": 1}`,
+		"testdata/c.golden": "\"s\"\n",
+	}), func(dir string) {
+		wd, err := os.Getwd()
+		require.NoError(t, err)
+		require.NoError(t, os.Chdir(dir))
+		defer func() { _ = os.Chdir(wd) }()
+
+		assert.True(t, AssertGolden(t, "a", []byte("x")))
+		assert.True(t, AssertGoldenJSON(t, "b", `{"a":1}`))
+		assert.True(t, AssertGoldenValue(t, "c", "s"))
+	})
+}
@@ -0,0 +1,244 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const defaultGoroutineLeakGracePeriod = time.Second
+
+// These match goroutines that belong to the Go runtime or the testing framework, rather than
+// to the code under test.
+func defaultIgnoredGoroutinePatterns() []string {
+	return []string{
+		`testing\.tRunner\(`,
+		`testing\.\(\*T\)\.Run\(`,
+		`testing\.\(\*M\)\.`,
+		`testing\.runFuzzing\(`,
+		`os/signal\.signal_recv\(`,
+		`runtime\.ensureSigM\(`,
+		`runtime/trace\.`,
+	}
+}
+
+// GoroutineLeakOption is a common interface for optional configuration parameters that can be
+// used with CheckGoroutineLeaks.
+type GoroutineLeakOption interface {
+	apply(c *goroutineLeakConfig)
+}
+
+type goroutineLeakConfig struct {
+	gracePeriod time.Duration
+	ignore      []*regexp.Regexp
+}
+
+type gracePeriodGoroutineLeakOption time.Duration
+
+func (o gracePeriodGoroutineLeakOption) apply(c *goroutineLeakConfig) {
+	c.gracePeriod = time.Duration(o)
+}
+
+type ignoreGoroutineLeakOption string
+
+func (o ignoreGoroutineLeakOption) apply(c *goroutineLeakConfig) {
+	c.ignore = append(c.ignore, regexp.MustCompile(string(o)))
+}
+
+// GoroutineLeakOptionGracePeriod returns an option that sets how long CheckGoroutineLeaks will
+// keep rechecking for goroutines to exit before it reports them as leaked. The default is one
+// second.
+func GoroutineLeakOptionGracePeriod(gracePeriod time.Duration) GoroutineLeakOption {
+	return gracePeriodGoroutineLeakOption(gracePeriod)
+}
+
+// GoroutineLeakOptionIgnore returns an option that tells CheckGoroutineLeaks not to report any
+// goroutine whose stack trace matches the specified regular expression. For instance, a pattern
+// of `mypackage\.backgroundWorker\(` would ignore goroutines that are running that function.
+func GoroutineLeakOptionIgnore(pattern string) GoroutineLeakOption {
+	return ignoreGoroutineLeakOption(pattern)
+}
+
+type goroutineInfo struct {
+	id    int64
+	stack string
+}
+
+// CheckGoroutineLeaks takes a snapshot of the currently running goroutines, and registers a
+// cleanup function that will look for any new goroutines that are still running at the end of
+// the test. If there are any, it keeps rechecking until a grace period has elapsed (see
+// GoroutineLeakOptionGracePeriod), and then reports a failure that includes each leaked
+// goroutine's stack trace.
+//
+// Goroutines that belong to the Go runtime or the testing framework are not reported, nor are
+// any that match a pattern specified with GoroutineLeakOptionIgnore.
+//
+//	func TestSomething(t *testing.T) {
+//	    helpers.CheckGoroutineLeaks(t)
+//	    // ...test logic that should not leave any goroutines behind
+//	}
+//
+// Since any goroutine that starts during the test is a candidate, this should not be used in
+// tests that call t.Parallel().
+//
+// The test must have a Cleanup method, as *testing.T and the TestingT instances created by the
+// testbox package do; if it does not, the test fails immediately.
+func CheckGoroutineLeaks(t require.TestingT, options ...GoroutineLeakOption) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	ct, ok := t.(interface{ Cleanup(func()) })
+	if !ok {
+		t.Errorf("CheckGoroutineLeaks requires a test that has a Cleanup method")
+		t.FailNow()
+		return
+	}
+	config := goroutineLeakConfig{gracePeriod: defaultGoroutineLeakGracePeriod}
+	for _, p := range defaultIgnoredGoroutinePatterns() {
+		config.ignore = append(config.ignore, regexp.MustCompile(p))
+	}
+	for _, o := range options {
+		o.apply(&config)
+	}
+	existing := make(map[int64]bool)
+	for _, g := range getAllGoroutines() {
+		existing[g.id] = true
+	}
+	ct.Cleanup(func() {
+		if t, ok := t.(interface{ Helper() }); ok {
+			t.Helper()
+		}
+		deadline := time.Now().Add(ScaledTimeout(config.gracePeriod))
+		for {
+			leaked := findLeakedGoroutines(existing, config.ignore)
+			if len(leaked) == 0 {
+				return
+			}
+			if time.Now().After(deadline) {
+				stacks := make([]string, 0, len(leaked))
+				for _, g := range leaked {
+					stacks = append(stacks, g.stack)
+				}
+				t.Errorf("found %d leaked goroutine(s) after waiting %s:\n\n%s",
+					len(leaked), describeTimeout(config.gracePeriod), strings.Join(stacks, "\n\n"))
+				return
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	})
+}
+
+func findLeakedGoroutines(existing map[int64]bool, ignore []*regexp.Regexp) []goroutineInfo {
+	currentID := getCurrentGoroutineID()
+	var ret []goroutineInfo
+GoroutineLoop:
+	for _, g := range getAllGoroutines() {
+		if existing[g.id] || g.id == currentID {
+			continue
+		}
+		for _, p := range ignore {
+			if p.MatchString(g.stack) {
+				continue GoroutineLoop
+			}
+		}
+		ret = append(ret, g)
+	}
+	return ret
+}
+
+// describeModuleGoroutines returns the stack traces of all goroutines, other than the excluded ones,
+// that have at least one frame from the main module: that is, the module under test. If the main
+// module cannot be determined, it includes all goroutines.
+func describeModuleGoroutines(exclude ...int64) string {
+	module := getMainModulePath()
+	var stacks []string
+	for _, g := range getAllGoroutines() {
+		if slices.Contains(exclude, g.id) {
+			continue
+		}
+		if module == "" || stackHasFrameFromModule(g.stack, module) {
+			stacks = append(stacks, g.stack)
+		}
+	}
+	switch {
+	case module == "":
+		return fmt.Sprintf("goroutine stacks:\n\n%s", strings.Join(stacks, "\n\n"))
+	case len(stacks) == 0:
+		return fmt.Sprintf("no other goroutines were running code from %s", module)
+	default:
+		return fmt.Sprintf("%d goroutine(s) running code from %s:\n\n%s",
+			len(stacks), module, strings.Join(stacks, "\n\n"))
+	}
+}
+
+func getMainModulePath() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Path
+	}
+	return ""
+}
+
+// stackHasFrameFromModule checks the function names in a goroutine's stack trace. These are the
+// lines that are not indented, other than the header and any "created by" line.
+func stackHasFrameFromModule(stack, module string) bool {
+	lines := strings.Split(stack, "\n")
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "created by ") {
+			continue
+		}
+		if strings.HasPrefix(line, module+".") || strings.HasPrefix(line, module+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func getAllGoroutines() []goroutineInfo {
+	return parseGoroutineStacks(getStackDump(true))
+}
+
+func getCurrentGoroutineID() int64 {
+	gs := parseGoroutineStacks(getStackDump(false))
+	if len(gs) == 0 {
+		return 0
+	}
+	return gs[0].id
+}
+
+func getStackDump(all bool) []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
+
+// parseGoroutineStacks splits the output of runtime.Stack into individual goroutines. Each one
+// begins with a header line such as "goroutine 7 [chan receive]:".
+func parseGoroutineStacks(dump []byte) []goroutineInfo {
+	var ret []goroutineInfo
+	for _, block := range bytes.Split(bytes.TrimSpace(dump), []byte("\n\n")) {
+		header, _, _ := bytes.Cut(block, []byte("\n"))
+		fields := strings.Fields(string(header))
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		id, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, goroutineInfo{id: id, stack: string(block)})
+	}
+	return ret
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func leakyGoroutine(release <-chan struct{}) {
+	<-release
+}
+
+func TestCheckGoroutineLeaksPassesIfNoGoroutinesAreLeft(t *testing.T) {
+	CheckGoroutineLeaks(t)
+	done := make(chan struct{})
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		close(done)
+	}()
+	<-done
+}
+
+func TestCheckGoroutineLeaksWaitsForGracePeriod(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		CheckGoroutineLeaks(t, GoroutineLeakOptionGracePeriod(time.Second))
+		release := make(chan struct{})
+		go leakyGoroutine(release)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			close(release)
+		}()
+	})
+	assert.False(t, result.Failed)
+}
+
+func TestCheckGoroutineLeaksReportsLeakedGoroutine(t *testing.T) {
+	withTimeoutScale(t, 1)
+	release := make(chan struct{})
+	defer close(release)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		CheckGoroutineLeaks(t, GoroutineLeakOptionGracePeriod(time.Millisecond*50))
+		go leakyGoroutine(release)
+	})
+	assert.True(t, result.Failed)
+	if assert.Len(t, result.Failures, 1) {
+		assert.Regexp(t, `^found 1 leaked goroutine\(s\) after waiting 50ms:\n\ngoroutine \d+ \[chan receive\]:\n`,
+			result.Failures[0].Message)
+		assert.Contains(t, result.Failures[0].Message, "v3.leakyGoroutine(")
+	}
+}
+
+func TestCheckGoroutineLeaksWithIgnorePattern(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		CheckGoroutineLeaks(t, GoroutineLeakOptionGracePeriod(time.Millisecond*50),
+			GoroutineLeakOptionIgnore(`v3\.leakyGoroutine\(`))
+		go leakyGoroutine(release)
+	})
+	assert.False(t, result.Failed)
+}
+
+func TestStackHasFrameFromModule(t *testing.T) {
+	stack := "goroutine 7 [chan receive]:\n" +
+		"example.com/other.wait(...)\n" +
+		"\t/src/example.com/mymodule/file.go:10 +0x1\n" +
+		"example.com/mymodule/sub.(*T).Run(0x0)\n" +
+		"\t/src/example.com/mymodule/sub/file.go:20 +0x2\n" +
+		"created by example.com/third.Start in goroutine 1\n" +
+		"\t/src/example.com/third/file.go:30 +0x3"
+	assert.True(t, stackHasFrameFromModule(stack, "example.com/mymodule"))
+	assert.True(t, stackHasFrameFromModule(stack, "example.com/other"))
+	assert.False(t, stackHasFrameFromModule(stack, "example.com/third"))
+	assert.False(t, stackHasFrameFromModule(stack, "example.com/my"))
+}
+
+func TestDescribeModuleGoroutines(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	go leakyGoroutine(release)
+	desc := RequireEventually(t, func() string { return describeModuleGoroutines(getCurrentGoroutineID()) },
+		matchers.StringContains("v3.leakyGoroutine("), time.Second, time.Millisecond)
+	assert.Regexp(t, `^\d+ goroutine\(s\) running code from github.com/launchdarkly/go-test-helpers/v3:\n\n`, desc)
+	assert.NotContains(t, desc, "v3.TestDescribeModuleGoroutines(")
+}
+
+func TestCheckGoroutineLeaksRequiresCleanup(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		CheckGoroutineLeaks(struct{ testbox.TestingT }{t}) // hides the Cleanup method
+	})
+	assert.True(t, result.Failed)
+	if assert.Len(t, result.Failures, 1) {
+		assert.Equal(t, "CheckGoroutineLeaks requires a test that has a Cleanup method", result.Failures[0].Message)
+	}
+}
@@ -9,49 +9,40 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
-	"fmt"
-	"log"
 	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
 )
 
-// WithSelfSignedServer is a convenience function for starting a test HTTPS server with a self-signed
-// certificate, running the specified function, and then closing the server and cleaning up the
-// temporary certificate files. If for some reason creating the server fails, it panics. The action
-// function's second and third parameters provide the CA certificate for configuring the client,
-// and a preconfigured CertPool in case that is more convenient to use.
-func WithSelfSignedServer(handler http.Handler, action func(*httptest.Server, []byte, *x509.CertPool)) {
-	certFile, err := os.CreateTemp("", "test")
-	if err != nil {
-		panic(fmt.Errorf("can't create temp file: %s", err))
-	}
-	_ = certFile.Close()
-	certFilePath := certFile.Name()
-	tryToDelete := func(path string) {
-		err := os.Remove(path)
-		if err != nil {
-			log.Printf("Unable to clean up temp file %s: %s", path, err)
-		}
-	}
-	defer tryToDelete(certFilePath)
-	keyFile, err := os.CreateTemp("", "test")
-	if err != nil {
-		panic(fmt.Errorf("can't create temp file: %s", err))
-	}
-	_ = keyFile.Close()
-	keyFilePath := keyFile.Name()
-	defer tryToDelete(keyFilePath)
-	err = MakeSelfSignedCert(certFilePath, keyFilePath)
-	if err != nil {
-		panic(fmt.Errorf("can't create self-signed certificate: %s", err))
+// TLSServer is a convenience function for starting a test HTTPS server with a self-signed
+// certificate. The server is closed, and the temporary certificate files are deleted, when the
+// test completes. If for some reason creating the server fails, the test fails and stops
+// immediately. The second and third return values provide the CA certificate for configuring the
+// client, and a preconfigured CertPool in case that is more convenient to use.
+//
+//	server, _, certPool := httphelpers.TLSServer(t, handler)
+//	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: certPool}}}
+func TLSServer(t helpers.CleanupT, handler http.Handler) (*httptest.Server, []byte, *x509.CertPool) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	certFilePath := helpers.TempFile(t)
+	keyFilePath := helpers.TempFile(t)
+	if err := MakeSelfSignedCert(certFilePath, keyFilePath); err != nil {
+		t.Errorf("can't create self-signed certificate: %s", err)
+		t.FailNow()
+		return nil, nil, nil
 	}
 	certData, err := os.ReadFile(certFilePath)
 	if err != nil {
-		panic(fmt.Errorf("can't read self-signed certificate: %s", err))
+		t.Errorf("can't read self-signed certificate: %s", err)
+		t.FailNow()
+		return nil, nil, nil
 	}
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
@@ -60,11 +51,28 @@ func WithSelfSignedServer(handler http.Handler, action func(*httptest.Server, []
 	certPool.AppendCertsFromPEM(certData)
 	server, err := MakeServerWithCert(certFilePath, keyFilePath, handler)
 	if err != nil {
-		panic(fmt.Errorf("can't start HTTPS server: %s", err))
-	}
-	defer server.Close()
-	defer server.CloseClientConnections()
-	action(server, certData, certPool)
+		t.Errorf("can't start HTTPS server: %s", err)
+		t.FailNow()
+		return nil, nil, nil
+	}
+	t.Cleanup(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+	return server, certData, certPool
+}
+
+// WithSelfSignedServer is a convenience function for starting a test HTTPS server with a self-signed
+// certificate, running the specified function, and then closing the server and cleaning up the
+// temporary certificate files. If for some reason creating the server fails, it panics. The action
+// function's second and third parameters provide the CA certificate for configuring the client,
+// and a preconfigured CertPool in case that is more convenient to use.
+//
+// To close the server at the end of the test instead, use TLSServer.
+func WithSelfSignedServer(handler http.Handler, action func(*httptest.Server, []byte, *x509.CertPool)) {
+	helpers.WithCleanupScope(func(t helpers.CleanupT) {
+		action(TLSServer(t, handler))
+	})
 }
 
 // MakeServerWithCert creates and starts a test HTTPS server using the specified certificate.
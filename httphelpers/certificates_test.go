@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -24,3 +26,24 @@ func TestSelfSignedServer(t *testing.T) {
 		assert.Equal(t, 200, resp.StatusCode)
 	})
 }
+
+func TestTLSServer(t *testing.T) {
+	handler := HandlerWithStatus(200)
+	var url string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		server, certData, certs := TLSServer(t, handler)
+		assert.NotEmpty(t, certData)
+		url = server.URL
+		client := *http.DefaultClient
+		transport := &http.Transport{}
+		transport.TLSClientConfig = &tls.Config{RootCAs: certs}
+		client.Transport = transport
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+	assert.False(t, result.Failed)
+	_, err := http.DefaultClient.Get(url)
+	require.Error(t, err) // server is no longer listening
+}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"regexp"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
 )
 
 // HTTPRequestInfo represents a request captured by NewRecordingHTTPHandler.
@@ -126,6 +128,8 @@ func HandlerWithStatus(status int) http.Handler {
 }
 
 // RecordingHandler wraps any HTTP handler in another handler that pushes received requests onto a channel.
+// There is no limit on how many requests can be pushed; the handler never blocks waiting for the test to
+// read from the channel.
 //
 //	handler, requestsCh := httphelpers.RecordingHandler(httphelpers.HandlerWithStatus(200))
 //	httphelpers.WithServer(handler, func(server *http.TestServer) {
@@ -133,13 +137,35 @@ func HandlerWithStatus(status int) http.Handler {
 //	    r := <-requestsCh
 //	    verifyRequestPropertiesWereCorrect(r.Request, r.Body)
 //	})
+//
+// To inspect or wait for requests in other ways, use RecordingHandlerWithRecorder.
+//
+// Requests that the test has not read yet are held in memory by a background goroutine, which keeps
+// running until they have all been read. If that is a problem, for instance if the test checks for
+// goroutine leaks, use RecordingHandlerWithRecorder with a Recorder that the test closes when it is
+// done.
 func RecordingHandler(delegateToHandler http.Handler) (http.Handler, <-chan HTTPRequestInfo) {
-	requestsCh := make(chan HTTPRequestInfo, 100)
-	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestsCh <- HTTPRequestInfo{r, getRequestBody(r)}
+	recorder := helpers.NewRecorder[HTTPRequestInfo]()
+	return RecordingHandlerWithRecorder(delegateToHandler, recorder), recorder.Chan()
+}
+
+// RecordingHandlerWithRecorder wraps any HTTP handler in another handler that adds received requests
+// to a helpers.Recorder.
+//
+//	requests := helpers.NewRecorder[httphelpers.HTTPRequestInfo]()
+//	t.Cleanup(requests.Close)
+//	handler := httphelpers.RecordingHandlerWithRecorder(httphelpers.HandlerWithStatus(200), requests)
+//	server := httphelpers.Server(t, handler)
+//	doSomethingThatMakesTwoRequests(server.URL)
+//	requestInfos := requests.WaitForCount(t, 2, time.Second)
+func RecordingHandlerWithRecorder(
+	delegateToHandler http.Handler,
+	recorder *helpers.Recorder[HTTPRequestInfo],
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder.Add(HTTPRequestInfo{r, getRequestBody(r)})
 		delegateToHandler.ServeHTTP(w, r)
 	})
-	return handler, requestsCh
 }
 
 // SequentialHandler creates an HTTP handler that delegates to one handler per request, in the order given.
@@ -189,3 +215,50 @@ func BrokenConnectionHandler() http.Handler {
 			" here from the Go HTTP framework is expected and can be ignored")
 	})
 }
+
+// HandlerWithFaultyBody wraps any HTTP handler so that the response body it writes is passed
+// through the specified faults (see helpers.FaultyWriter). If a write fails, the handler is
+// aborted with http.ErrAbortHandler, so an httptest.Server will close the connection and the
+// client will see a truncated response; a client created with ClientFromHandler will get an
+// error instead.
+//
+// The wrapped handler can still use Flush, so this can be used with streaming handlers.
+//
+//	handler := httphelpers.HandlerWithFaultyBody(
+//	    httphelpers.HandlerWithResponse(200, nil, data),
+//	    helpers.WriteFailAfter(10, errors.New("simulated I/O error")))
+func HandlerWithFaultyBody(handler http.Handler, faults ...helpers.WriterFault) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(&faultyResponseWriter{ResponseWriter: w, body: helpers.FaultyWriter(w, faults...)}, r)
+	})
+}
+
+type faultyResponseWriter struct {
+	http.ResponseWriter
+	body io.Writer
+}
+
+func (f *faultyResponseWriter) Write(data []byte) (int, error) {
+	n, err := f.body.Write(data)
+	if err != nil {
+		if flusher, ok := f.ResponseWriter.(http.Flusher); ok {
+			flusher.Flush() // make sure whatever we did write gets to the client before we abort
+		}
+		panic(http.ErrAbortHandler)
+	}
+	return n, nil
+}
+
+func (f *faultyResponseWriter) Flush() {
+	if flusher, ok := f.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (f *faultyResponseWriter) CloseNotify() <-chan bool {
+	// CloseNotifier is deprecated, but ChunkedStreamingHandler uses it
+	if closeNotifier, ok := f.ResponseWriter.(http.CloseNotifier); ok { //nolint:staticcheck
+		return closeNotifier.CloseNotify()
+	}
+	return nil
+}
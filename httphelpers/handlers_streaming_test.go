@@ -37,7 +37,8 @@ func TestChunkedStreamingHandlerSend(t *testing.T) {
 	WithServer(handler, func(server *httptest.Server) {
 		resp1, err := http.DefaultClient.Get(server.URL)
 		require.NoError(t, err)
-		defer resp1.Body.Close()
+		body1 := helpers.NewTimedReader(resp1.Body)
+		defer body1.Close()
 
 		assert.Equal(t, 200, resp1.StatusCode)
 		assert.Equal(t, "text/plain", resp1.Header.Get("Content-Type"))
@@ -47,22 +48,30 @@ func TestChunkedStreamingHandlerSend(t *testing.T) {
 		stream.Send([]byte("third,"))
 
 		expected := "hello,first,second,third,"
-		assert.Equal(t, expected, string(helpers.ReadWithTimeout(resp1.Body, len(expected), time.Second)))
+		assertReadN(t, body1, expected)
 
 		resp2, err := http.DefaultClient.Get(server.URL)
 		require.NoError(t, err)
-		defer resp2.Body.Close()
+		body2 := helpers.NewTimedReader(resp2.Body)
+		defer body2.Close()
 
 		expected = "hello,"
-		assert.Equal(t, expected, string(helpers.ReadWithTimeout(resp2.Body, len(expected), time.Second)))
+		assertReadN(t, body2, expected)
 
 		stream.Send([]byte("fourth."))
 		expected = "fourth."
-		assert.Equal(t, expected, string(helpers.ReadWithTimeout(resp1.Body, len(expected), time.Second)))
-		assert.Equal(t, expected, string(helpers.ReadWithTimeout(resp2.Body, len(expected), time.Second)))
+		assertReadN(t, body1, expected)
+		assertReadN(t, body2, expected)
 	})
 }
 
+func assertReadN(t *testing.T, tr *helpers.TimedReader, expected string) {
+	t.Helper()
+	data, err := tr.ReadN(len(expected), time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+}
+
 func TestChunkedStreamingHandlerEndAll(t *testing.T) {
 	initialData := []byte("hello,")
 	handler, stream := ChunkedStreamingHandler(initialData, "text/plain")
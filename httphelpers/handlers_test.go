@@ -2,11 +2,17 @@ package httphelpers
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDelegatingHandler(t *testing.T) {
@@ -164,6 +170,21 @@ func TestRecordingHandler(t *testing.T) {
 	assert.Equal(t, postData, ri2.Body)
 }
 
+func TestRecordingHandlerWithRecorder(t *testing.T) {
+	h := HandlerWithStatus(418)
+	recorder := helpers.NewRecorder[HTTPRequestInfo]()
+	rh := RecordingHandlerWithRecorder(h, recorder)
+
+	req1, _ := http.NewRequest("GET", "/1", nil)
+	rh.ServeHTTP(httptest.NewRecorder(), req1)
+	req2, _ := http.NewRequest("GET", "/2", nil)
+	rh.ServeHTTP(httptest.NewRecorder(), req2)
+
+	requests := recorder.WaitForCount(t, 2, time.Second)
+	assert.Equal(t, req1.URL.Path, requests[0].Request.URL.Path)
+	assert.Equal(t, req2.URL.Path, requests[1].Request.URL.Path)
+}
+
 func TestSequentialHandler(t *testing.T) {
 	h1 := HandlerWithStatus(500)
 	h2 := HandlerWithStatus(400)
@@ -202,3 +223,34 @@ func TestBrokenConnectionHandler(t *testing.T) {
 		})
 	})
 }
+
+func TestHandlerWithFaultyBody(t *testing.T) {
+	h := HandlerWithFaultyBody(HandlerWithResponse(200, nil, []byte("hello world")),
+		helpers.WriteFailAfter(5, errors.New("sorry")))
+
+	t.Run("with instrumented client", func(t *testing.T) {
+		client := ClientFromHandler(h)
+		_, err := client.Get("/")
+		assert.Error(t, err)
+	})
+
+	t.Run("with server", func(t *testing.T) {
+		WithServer(h, func(server *httptest.Server) {
+			resp, err := http.DefaultClient.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			data, err := io.ReadAll(resp.Body)
+			assert.Equal(t, "hello", string(data))
+			assert.Error(t, err)
+		})
+	})
+
+	t.Run("no error", func(t *testing.T) {
+		h := HandlerWithFaultyBody(HandlerWithResponse(200, nil, []byte("hello world")),
+			helpers.WriteLatency(time.Millisecond))
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		h.ServeHTTP(rr, req)
+		assert.Equal(t, "hello world", rr.Body.String())
+	})
+}
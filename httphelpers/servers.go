@@ -3,13 +3,30 @@ package httphelpers
 import (
 	"net/http"
 	"net/http/httptest"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
 )
 
+// Server creates an httptest.Server from the given handler, and ensures that the server is closed
+// when the test completes.
+//
+//	server := httphelpers.Server(t, handler)
+//	resp, err := http.Get(server.URL)
+func Server(t helpers.CleanupT, handler http.Handler) *httptest.Server {
+	server := httptest.NewServer(handler)
+	t.Cleanup(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+	return server
+}
+
 // WithServer creates an httptest.Server from the given handler, passes the server instance to the given
 // function, and ensures that the server is closed afterward.
+//
+// To close the server at the end of the test instead, use Server.
 func WithServer(handler http.Handler, action func(*httptest.Server)) {
-	server := httptest.NewServer(handler)
-	defer server.Close()
-	defer server.CloseClientConnections()
-	action(server)
+	helpers.WithCleanupScope(func(t helpers.CleanupT) {
+		action(Server(t, handler))
+	})
 }
@@ -5,6 +5,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,3 +24,19 @@ func TestWithServer(t *testing.T) {
 	_, err := http.DefaultClient.Get(url)
 	require.Error(t, err) // server is no longer listening
 }
+
+func TestServer(t *testing.T) {
+	handler := HandlerWithStatus(200)
+	var url string
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		server := Server(t, handler)
+		url = server.URL
+		resp, err := http.DefaultClient.Get(url)
+		require.NoError(t, err)
+		require.NotNil(t, resp)
+		assert.Equal(t, 200, resp.StatusCode)
+	})
+	assert.False(t, result.Failed)
+	_, err := http.DefaultClient.Get(url)
+	require.Error(t, err) // server is no longer listening
+}
@@ -1,55 +1,276 @@
 package helpers
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"os"
 	"time"
 )
 
-// ReadWithTimeout reads data until it gets the desired number of bytes or times out.
+const timedReaderChunkSize = 4096
+
+// ErrReadTimeout is the error that is wrapped by PartialReadError if a TimedReader operation
+// timed out.
+var ErrReadTimeout = errors.New("read timed out")
+
+// PartialReadError is returned by TimedReader methods if they could not read all of the desired
+// data, either because of a timeout or because the underlying reader returned an error. Data
+// contains whatever was received before the failure.
+type PartialReadError struct {
+	// Data is the data that was received before the failure.
+	Data []byte
+	// Err is ErrReadTimeout if the operation timed out, or else the error from the underlying reader.
+	Err error
+}
+
+// Error returns a description of the error, including the data that was received.
+func (e *PartialReadError) Error() string {
+	return fmt.Sprintf("%s after receiving %d byte(s): %q", e.Err, len(e.Data), e.Data)
+}
+
+// Unwrap returns the underlying error, so that errors.Is(err, ErrReadTimeout) or
+// errors.Is(err, io.EOF) can be used.
+func (e *PartialReadError) Unwrap() error {
+	return e.Err
+}
+
+// TimedReader wraps an io.Reader to provide reads with timeouts.
 //
-// This is an inefficient implementation that should only be used in tests.
-func ReadWithTimeout(r io.Reader, n int, timeout time.Duration) []byte {
-	byteCh := make(chan byte)
-	closer := make(chan struct{})
+// If the reader is a net.Conn, an *os.File, or any other type that supports SetReadDeadline, the
+// timeouts are implemented with read deadlines. Otherwise, reads are done by a single background
+// goroutine; if a read times out, the data it eventually returns is kept for the next operation,
+// so nothing is lost. Call Close to stop the background goroutine.
+//
+// Any data that is read past the end of what an operation needed (for instance, data after the
+// delimiter in ReadUntil) is also kept for the next operation.
+//
+// A TimedReader is not safe for concurrent use by multiple goroutines.
+//
+//	tr := helpers.NewTimedReader(resp.Body)
+//	defer tr.Close()
+//	line, err := tr.ReadLine(time.Second)
+type TimedReader struct {
+	reader    io.Reader
+	deadliner interface{ SetReadDeadline(time.Time) error }
+	pending   []byte
+	err       error
+	pump      *readPump
+}
+
+type readPump struct {
+	requests chan int
+	results  chan readPumpResult
+	done     chan struct{}
+	inFlight bool
+}
+
+type readPumpResult struct {
+	data []byte
+	err  error
+}
+
+// NewTimedReader creates a TimedReader.
+func NewTimedReader(r io.Reader) *TimedReader {
+	tr := &TimedReader{reader: r}
+	if d, ok := r.(interface{ SetReadDeadline(time.Time) error }); ok {
+		// This will fail if the reader doesn't really support deadlines, such as a regular file
+		if err := d.SetReadDeadline(time.Time{}); err == nil {
+			tr.deadliner = d
+		}
+	}
+	return tr
+}
+
+// ReadN reads exactly n bytes. If the timeout expires or the reader returns an error first, it
+// returns the data that it did receive along with a *PartialReadError.
+func (tr *TimedReader) ReadN(n int, timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	defer tr.clearDeadline()
+	buf := make([]byte, 0, n)
+	for len(buf) < n {
+		data, err := tr.readChunk(n-len(buf), deadline)
+		if err != nil {
+			return buf, &PartialReadError{Data: buf, Err: err}
+		}
+		buf = append(buf, data...)
+	}
+	return buf, nil
+}
+
+// ReadUntil reads until it encounters the delimiter byte, and returns the data up to and
+// including the delimiter. If the timeout expires or the reader returns an error first, it
+// returns the data that it did receive along with a *PartialReadError.
+func (tr *TimedReader) ReadUntil(delim byte, timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	defer tr.clearDeadline()
+	var buf []byte
+	for {
+		data, err := tr.readChunk(timedReaderChunkSize, deadline)
+		if err != nil {
+			return buf, &PartialReadError{Data: buf, Err: err}
+		}
+		if i := bytes.IndexByte(data, delim); i >= 0 {
+			tr.unread(data[i+1:])
+			return append(buf, data[:i+1]...), nil
+		}
+		buf = append(buf, data...)
+	}
+}
+
+// ReadLine reads a line of text and returns it without the line terminator, which can be either
+// "\n" or "\r\n". If the timeout expires or the reader returns an error first, it returns the
+// data that it did receive along with a *PartialReadError.
+func (tr *TimedReader) ReadLine(timeout time.Duration) (string, error) {
+	data, err := tr.ReadUntil('\n', timeout)
+	if err != nil {
+		return string(data), err
+	}
+	return string(bytes.TrimSuffix(bytes.TrimSuffix(data, []byte("\n")), []byte("\r"))), nil
+}
+
+// ReadAll reads until the reader returns io.EOF. If the timeout expires or the reader returns
+// some other error first, it returns the data that it did receive along with a *PartialReadError.
+func (tr *TimedReader) ReadAll(timeout time.Duration) ([]byte, error) {
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	defer tr.clearDeadline()
+	var buf []byte
+	for {
+		data, err := tr.readChunk(timedReaderChunkSize, deadline)
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err != nil {
+			return buf, &PartialReadError{Data: buf, Err: err}
+		}
+		buf = append(buf, data...)
+	}
+}
+
+// Close stops the background goroutine, if any, and closes the underlying reader if it
+// implements io.Closer.
+func (tr *TimedReader) Close() error {
+	tr.stopPump()
+	if c, ok := tr.reader.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (tr *TimedReader) unread(data []byte) {
+	if len(data) != 0 {
+		tr.pending = append(append([]byte(nil), data...), tr.pending...)
+	}
+}
+
+// readChunk returns up to maxSize bytes. It returns either non-empty data or an error, not both.
+func (tr *TimedReader) readChunk(maxSize int, deadline time.Time) ([]byte, error) {
+	if len(tr.pending) != 0 {
+		n := min(maxSize, len(tr.pending))
+		data := tr.pending[:n]
+		tr.pending = tr.pending[n:]
+		return data, nil
+	}
+	for tr.err == nil {
+		var data []byte
+		var err error
+		if tr.deadliner != nil {
+			data, err = tr.readWithDeadline(maxSize, deadline)
+		} else {
+			data, err = tr.readWithPump(maxSize, deadline)
+		}
+		if len(data) > maxSize {
+			tr.unread(data[maxSize:])
+			data = data[:maxSize]
+		}
+		if err != nil && !errors.Is(err, ErrReadTimeout) {
+			tr.err = err // we'll return this error on the next call, after returning any data we got
+		}
+		if len(data) != 0 {
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nil, tr.err
+}
+
+func (tr *TimedReader) readWithDeadline(maxSize int, deadline time.Time) ([]byte, error) {
+	if err := tr.deadliner.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxSize)
+	n, err := tr.reader.Read(buf)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = ErrReadTimeout
+	}
+	return buf[:n], err
+}
+
+func (tr *TimedReader) readWithPump(maxSize int, deadline time.Time) ([]byte, error) {
+	if tr.pump == nil {
+		tr.pump = startReadPump(tr.reader)
+	}
+	if !tr.pump.inFlight {
+		tr.pump.requests <- maxSize
+		tr.pump.inFlight = true
+	}
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case result := <-tr.pump.results:
+		tr.pump.inFlight = false
+		return result.data, result.err
+	case <-timer.C:
+		return nil, ErrReadTimeout
+	}
+}
 
+func (tr *TimedReader) clearDeadline() {
+	if tr.deadliner != nil {
+		_ = tr.deadliner.SetReadDeadline(time.Time{})
+	}
+}
+
+func (tr *TimedReader) stopPump() {
+	if tr.pump != nil {
+		close(tr.pump.done)
+		tr.pump = nil
+	}
+}
+
+func startReadPump(r io.Reader) *readPump {
+	p := &readPump{
+		requests: make(chan int),
+		results:  make(chan readPumpResult, 1), // buffered so the goroutine can exit even if no one is waiting
+		done:     make(chan struct{}),
+	}
 	go func() {
-		count := 0
 		for {
 			select {
-			case <-closer:
-				return
-			default:
-				b := make([]byte, 1)
-				got, err := r.Read(b)
+			case n := <-p.requests:
+				buf := make([]byte, n)
+				got, err := r.Read(buf)
+				p.results <- readPumpResult{data: buf[:got], err: err}
 				if err != nil {
 					return
 				}
-				if got > 0 {
-					byteCh <- b[0]
-				}
-				count++
-				if count >= n {
-					return
-				}
+			case <-p.done:
+				return
 			}
 		}
 	}()
+	return p
+}
 
-	buf := make([]byte, 0, n)
-	deadline := time.After(timeout)
-
-ReadLoop:
-	for {
-		select {
-		case b := <-byteCh:
-			buf = append(buf, b)
-			if len(buf) >= n {
-				break ReadLoop
-			}
-		case <-deadline:
-			break ReadLoop
-		}
-	}
-	close(closer)
-	return buf
+// ReadWithTimeout reads data until it gets the desired number of bytes or times out.
+//
+// Deprecated: Use TimedReader, which is more efficient and reports errors.
+func ReadWithTimeout(r io.Reader, n int, timeout time.Duration) []byte {
+	tr := NewTimedReader(r)
+	defer tr.stopPump()
+	data, _ := tr.ReadN(n, timeout)
+	return data
 }
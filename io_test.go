@@ -2,11 +2,15 @@ package helpers
 
 import (
 	"bytes"
+	"errors"
 	"io"
+	"net"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadWithTimeout(t *testing.T) {
@@ -41,3 +45,134 @@ func TestReadWithTimeout(t *testing.T) {
 	time.Sleep(time.Millisecond * 100)
 	assert.Equal(t, "good", string(data))
 }
+
+func TestTimedReaderReadN(t *testing.T) {
+	t.Run("all data available", func(t *testing.T) {
+		tr := NewTimedReader(bytes.NewBufferString("hello"))
+		data, err := tr.ReadN(5, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(data))
+	})
+
+	t.Run("reader returns EOF", func(t *testing.T) {
+		tr := NewTimedReader(bytes.NewBufferString("he"))
+		data, err := tr.ReadN(5, time.Second)
+		assert.Equal(t, "he", string(data))
+		var pe *PartialReadError
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, "he", string(pe.Data))
+		}
+		assert.True(t, errors.Is(err, io.EOF), "%s", err)
+		assert.Equal(t, `EOF after receiving 2 byte(s): "he"`, err.Error())
+	})
+
+	t.Run("timeout does not lose data", func(t *testing.T) {
+		r, w := io.Pipe()
+		tr := NewTimedReader(r)
+		defer tr.Close()
+		go func() {
+			_, _ = w.Write([]byte("good"))
+		}()
+		data, err := tr.ReadN(7, time.Millisecond*50)
+		assert.Equal(t, "good", string(data))
+		assert.True(t, errors.Is(err, ErrReadTimeout), "%s", err)
+
+		go func() {
+			_, _ = w.Write([]byte("bye"))
+		}()
+		data, err = tr.ReadN(3, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "bye", string(data))
+	})
+}
+
+func TestTimedReaderReadUntilAndReadLine(t *testing.T) {
+	tr := NewTimedReader(bytes.NewBufferString("first\r\nsecond\nthird;fourth"))
+	line, err := tr.ReadLine(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", line)
+
+	line, err = tr.ReadLine(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "second", line)
+
+	data, err := tr.ReadUntil(';', time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "third;", string(data))
+
+	line, err = tr.ReadLine(time.Second)
+	assert.Equal(t, "fourth", line)
+	assert.True(t, errors.Is(err, io.EOF), "%s", err)
+}
+
+func TestTimedReaderReadAll(t *testing.T) {
+	tr := NewTimedReader(bytes.NewBufferString("hello"))
+	data, err := tr.ReadAll(time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	r, w := io.Pipe()
+	tr = NewTimedReader(r)
+	defer tr.Close()
+	go func() {
+		_, _ = w.Write([]byte("partial"))
+	}()
+	data, err = tr.ReadAll(time.Millisecond * 50)
+	assert.Equal(t, "partial", string(data))
+	assert.True(t, errors.Is(err, ErrReadTimeout), "%s", err)
+}
+
+func TestTimedReaderWithDeadlines(t *testing.T) {
+	t.Run("net.Conn", func(t *testing.T) {
+		c1, c2 := net.Pipe()
+		defer c2.Close()
+		tr := NewTimedReader(c1)
+		defer tr.Close()
+		assert.NotNil(t, tr.deadliner)
+
+		go func() {
+			_, _ = c2.Write([]byte("hello\nwor"))
+		}()
+		line, err := tr.ReadLine(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", line)
+
+		line, err = tr.ReadLine(time.Millisecond * 50)
+		assert.Equal(t, "wor", line)
+		assert.True(t, errors.Is(err, ErrReadTimeout), "%s", err)
+
+		go func() {
+			_, _ = c2.Write([]byte("ld\n"))
+		}()
+		line, err = tr.ReadLine(time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "ld", line)
+	})
+
+	t.Run("*os.File", func(t *testing.T) {
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		defer w.Close()
+		tr := NewTimedReader(r)
+		defer tr.Close()
+
+		_, _ = w.Write([]byte("abc"))
+		data, err := tr.ReadN(5, time.Millisecond*50)
+		assert.Equal(t, "abc", string(data))
+		assert.True(t, errors.Is(err, ErrReadTimeout), "%s", err)
+	})
+
+	t.Run("regular file falls back to background reads", func(t *testing.T) {
+		WithTempFileData([]byte("abc"), func(path string) {
+			f, err := os.Open(path)
+			require.NoError(t, err)
+			tr := NewTimedReader(f)
+			defer tr.Close()
+			assert.Nil(t, tr.deadliner)
+
+			data, err := tr.ReadAll(time.Second)
+			assert.NoError(t, err)
+			assert.Equal(t, "abc", string(data))
+		})
+	})
+}
@@ -0,0 +1,297 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// LogEntry is a log message that was captured by CaptureStdLog or a CapturingSlogHandler.
+type LogEntry struct {
+	// Level is the log level. Messages from the standard log package, which has no levels, are
+	// given slog.LevelInfo.
+	Level slog.Level
+	// Message is the log message, not including any prefix, timestamp, or trailing newline.
+	Message string
+	// Attrs contains the attributes of a slog record, if any. Attributes within groups are
+	// flattened into keys like "group.key". Values are as returned by slog.Value.Any, so for
+	// instance all signed integers are int64.
+	Attrs map[string]any
+	// Time is the time when the message was logged.
+	Time time.Time
+}
+
+// String returns a description of the entry in the format "LEVEL message key1=value1 key2=value2",
+// with the attributes sorted by key.
+func (e LogEntry) String() string {
+	var b strings.Builder
+	b.WriteString(e.Level.String())
+	b.WriteString(" ")
+	b.WriteString(e.Message)
+	keys := make([]string, 0, len(e.Attrs))
+	for k := range e.Attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, e.Attrs[k])
+	}
+	return b.String()
+}
+
+// LogCapture holds log entries that were captured by CaptureStdLog or a CapturingSlogHandler, and
+// provides methods for querying them and making assertions about them. It is safe for concurrent
+// use.
+type LogCapture struct {
+	entries *Recorder[LogEntry]
+}
+
+func newLogCapture() *LogCapture {
+	return &LogCapture{entries: NewRecorder[LogEntry]()}
+}
+
+// Entries returns all of the entries that have been captured so far, in the order they were logged.
+func (c *LogCapture) Entries() []LogEntry {
+	return c.entries.Snapshot()
+}
+
+// Find returns all of the captured entries that match the matcher, in the order they were logged.
+//
+//	warnings := capture.Find(helpers.LogLevel(slog.LevelWarn))
+func (c *LogCapture) Find(matcher matchers.Matcher) []LogEntry {
+	var ret []LogEntry
+	for _, e := range c.entries.Snapshot() {
+		if pass, _ := matcher.Test(e); pass {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// AssertLogged asserts that at least one of the captured entries matches the matcher. On failure,
+// it lists all of the captured entries.
+//
+//	capture.AssertLogged(t, matchers.AllOf(
+//	    helpers.LogLevel(slog.LevelError),
+//	    helpers.LogMessageContains("connection failed")))
+func (c *LogCapture) AssertLogged(t assert.TestingT, matcher matchers.Matcher, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	all := c.entries.Snapshot()
+	for _, e := range all {
+		if pass, _ := matcher.Test(e); pass {
+			return true
+		}
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs,
+		"expected a log entry matching (%s), but there was none; %s", matcher.Describe(), describeLogEntries(all))
+	return false
+}
+
+// AssertNotLogged asserts that none of the captured entries match the matcher. On failure, it lists
+// the entries that matched, and all of the captured entries.
+func (c *LogCapture) AssertNotLogged(t assert.TestingT, matcher matchers.Matcher, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	all := c.entries.Snapshot()
+	var found []string
+	for _, e := range all {
+		if pass, _ := matcher.Test(e); pass {
+			found = append(found, e.String())
+		}
+	}
+	if len(found) == 0 {
+		return true
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs,
+		"expected no log entry matching (%s), but found %d:\n%s\n%s",
+		matcher.Describe(), len(found), strings.Join(found, "\n"), describeLogEntries(all))
+	return false
+}
+
+func (c *LogCapture) add(e LogEntry) {
+	c.entries.Add(e)
+}
+
+func describeLogEntries(entries []LogEntry) string {
+	if len(entries) == 0 {
+		return "no log output was captured"
+	}
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, e.String())
+	}
+	return "captured log output:\n" + strings.Join(lines, "\n")
+}
+
+// CaptureStdLog redirects the output of the standard log package so that it is captured, until
+// the test completes. Each call to a log function such as log.Printf becomes one LogEntry; the
+// logger's prefix and flags are temporarily cleared so that only the message is captured.
+//
+// Since the standard logger is global, this should not be used in tests that run in parallel with
+// other tests that log.
+//
+//	logs := helpers.CaptureStdLog(t)
+//	DoSomethingThatLogs()
+//	logs.AssertLogged(t, helpers.LogMessageContains("failed to close"))
+func CaptureStdLog(t CleanupT) *LogCapture {
+	c := newLogCapture()
+	oldWriter, oldFlags, oldPrefix := log.Writer(), log.Flags(), log.Prefix()
+	log.SetOutput(writerFunc(func(p []byte) (int, error) {
+		// The log package calls Write exactly once per message
+		c.add(LogEntry{
+			Level:   slog.LevelInfo,
+			Message: strings.TrimSuffix(string(p), "\n"),
+			Time:    time.Now(),
+		})
+		return len(p), nil
+	}))
+	log.SetFlags(0)
+	log.SetPrefix("")
+	t.Cleanup(func() {
+		log.SetOutput(oldWriter)
+		log.SetFlags(oldFlags)
+		log.SetPrefix(oldPrefix)
+	})
+	return c
+}
+
+// CapturingSlogHandler is an implementation of slog.Handler that captures all log records, for use
+// in testing code that logs with log/slog. It accepts records at all levels.
+//
+// Its embedded LogCapture provides methods for querying and making assertions about the records.
+// Handlers derived from it with WithAttrs or WithGroup share the same LogCapture.
+//
+//	handler := helpers.NewCapturingSlogHandler()
+//	thing := NewThingUnderTest(slog.New(handler))
+//	thing.DoSomething()
+//	handler.AssertLogged(t, helpers.LogAttr("user", matchers.Equal("x")))
+type CapturingSlogHandler struct {
+	*LogCapture
+	attrs       []slog.Attr
+	groupPrefix string
+}
+
+// NewCapturingSlogHandler creates a CapturingSlogHandler.
+func NewCapturingSlogHandler() *CapturingSlogHandler {
+	return &CapturingSlogHandler{LogCapture: newLogCapture()}
+}
+
+// Enabled returns true for all levels.
+func (h *CapturingSlogHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle captures a log record.
+func (h *CapturingSlogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := make(map[string]any)
+	for _, a := range h.attrs {
+		addSlogAttr(attrs, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addSlogAttr(attrs, h.groupPrefix, a)
+		return true
+	})
+	if len(attrs) == 0 {
+		attrs = nil
+	}
+	h.add(LogEntry{Level: r.Level, Message: r.Message, Attrs: attrs, Time: r.Time})
+	return nil
+}
+
+// WithAttrs returns a handler that adds the specified attributes to every record.
+func (h *CapturingSlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ret := *h
+	ret.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if h.groupPrefix != "" {
+			a = slog.Attr{Key: strings.TrimSuffix(h.groupPrefix, "."), Value: slog.GroupValue(a)}
+		}
+		ret.attrs = append(ret.attrs, a)
+	}
+	return &ret
+}
+
+// WithGroup returns a handler that puts all subsequent attributes in the specified group.
+func (h *CapturingSlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	ret := *h
+	ret.groupPrefix = h.groupPrefix + name + "."
+	return &ret
+}
+
+func addSlogAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	value := a.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range value.Group() {
+			addSlogAttr(attrs, groupPrefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	attrs[prefix+a.Key] = value.Any()
+}
+
+// LogLevel is a matcher for a LogEntry that tests whether it has the specified level.
+func LogLevel(level slog.Level) matchers.Matcher {
+	return matchers.Transform("level", func(value any) (any, error) {
+		return value.(LogEntry).Level, nil
+	}).EnsureInputValueType(LogEntry{}).Should(matchers.Equal(level))
+}
+
+// LogMessageContains is a matcher for a LogEntry that tests whether its message contains the
+// specified substring.
+func LogMessageContains(substring string) matchers.Matcher {
+	return logMessage().Should(matchers.StringContains(substring))
+}
+
+// LogMessageMatches is a matcher for a LogEntry that tests whether its message matches the
+// specified regular expression. It panics if the regular expression is invalid.
+func LogMessageMatches(pattern string) matchers.Matcher {
+	re := regexp.MustCompile(pattern)
+	return logMessage().Should(matchers.New(
+		func(value any) bool { return re.MatchString(value.(string)) },
+		func() string { return fmt.Sprintf("matches /%s/", pattern) },
+		func(any) string { return fmt.Sprintf("did not match /%s/", pattern) },
+	).EnsureType(""))
+}
+
+// LogAttr is a matcher for a LogEntry that tests whether it has an attribute with the specified
+// key whose value matches the matcher. For attributes within groups, use a key like "group.key".
+//
+// Attribute values are as returned by slog.Value.Any, so for instance all signed integers are
+// int64: use LogAttr("count", matchers.Equal(int64(1))) rather than matchers.Equal(1).
+func LogAttr(key string, matcher matchers.Matcher) matchers.Matcher {
+	return matchers.Transform(fmt.Sprintf("attribute %q", key), func(value any) (any, error) {
+		attrValue, ok := value.(LogEntry).Attrs[key]
+		if !ok {
+			return nil, fmt.Errorf("attribute %q was not present", key)
+		}
+		return attrValue, nil
+	}).EnsureInputValueType(LogEntry{}).Should(matcher)
+}
+
+func logMessage() matchers.MatcherTransform {
+	return matchers.Transform("message", func(value any) (any, error) {
+		return value.(LogEntry).Message, nil
+	}).EnsureInputValueType(LogEntry{})
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"errors"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureStdLog(t *testing.T) {
+	oldWriter, oldFlags, oldPrefix := log.Writer(), log.Flags(), log.Prefix()
+
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		logs := CaptureStdLog(t)
+		log.Printf("hello %s", "world")
+		WithCloser(&myCloser{err: errors.New("sorry")}, func() {})
+
+		entries := logs.Entries()
+		require.Len(t, entries, 2)
+		assert.Equal(t, slog.LevelInfo, entries[0].Level)
+		assert.Equal(t, "hello world", entries[0].Message)
+		assert.False(t, entries[0].Time.IsZero())
+		assert.Equal(t, "failed to close *helpers.myCloser: sorry", entries[1].Message)
+
+		logs.AssertLogged(t, LogMessageContains("failed to close"))
+		logs.AssertNotLogged(t, LogMessageContains("goodbye"))
+	})
+	assert.False(t, result.Failed)
+
+	assert.Equal(t, oldWriter, log.Writer())
+	assert.Equal(t, oldFlags, log.Flags())
+	assert.Equal(t, oldPrefix, log.Prefix())
+}
+
+func TestCapturingSlogHandler(t *testing.T) {
+	handler := NewCapturingSlogHandler()
+	logger := slog.New(handler)
+	logger.Debug("first", "a", 1)
+	logger.With("b", "x").WithGroup("g").Warn("second", "c", true, slog.Group("h", "d", 2.5))
+	logger.WithGroup("g").With("e", "y").Error("third")
+
+	entries := handler.Entries()
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, slog.LevelDebug, entries[0].Level)
+	assert.Equal(t, "first", entries[0].Message)
+	assert.Equal(t, map[string]any{"a": int64(1)}, entries[0].Attrs)
+
+	assert.Equal(t, slog.LevelWarn, entries[1].Level)
+	assert.Equal(t, map[string]any{"b": "x", "g.c": true, "g.h.d": 2.5}, entries[1].Attrs)
+	assert.Equal(t, "WARN second b=x g.c=true g.h.d=2.5", entries[1].String())
+
+	assert.Equal(t, map[string]any{"g.e": "y"}, entries[2].Attrs)
+}
+
+func TestLogCaptureFind(t *testing.T) {
+	handler := NewCapturingSlogHandler()
+	logger := slog.New(handler)
+	logger.Info("a1")
+	logger.Warn("b1", "n", 1)
+	logger.Warn("a2", "n", 2)
+
+	assert.Len(t, handler.Find(LogLevel(slog.LevelWarn)), 2)
+	assert.Len(t, handler.Find(LogMessageContains("a")), 2)
+	assert.Len(t, handler.Find(LogMessageMatches("^[ab]1$")), 2)
+	assert.Len(t, handler.Find(LogAttr("n", matchers.Equal(int64(2)))), 1)
+	assert.Len(t, handler.Find(matchers.AllOf(LogLevel(slog.LevelWarn), LogMessageContains("a"))), 1)
+	assert.Len(t, handler.Find(LogAttr("missing", matchers.Equal("x"))), 0)
+}
+
+func TestLogCaptureAssertLoggedFailure(t *testing.T) {
+	handler := NewCapturingSlogHandler()
+	logger := slog.New(handler)
+	logger.Info("a", "n", 1)
+	logger.Warn("b")
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		handler.AssertLogged(t, LogMessageMatches("^c"))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected a log entry matching (message matches /^c/), but there was none; "+
+		"captured log output:\nINFO a n=1\nWARN b",
+		result.Failures[0].Message)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		NewCapturingSlogHandler().AssertLogged(t, LogLevel(slog.LevelError))
+	})
+	require.True(t, result.Failed)
+	assert.Equal(t, "expected a log entry matching (level equal to ERROR), but there was none; "+
+		"no log output was captured",
+		result.Failures[0].Message)
+}
+
+func TestLogCaptureAssertNotLoggedFailure(t *testing.T) {
+	handler := NewCapturingSlogHandler()
+	logger := slog.New(handler)
+	logger.Info("a", "n", 1)
+	logger.Warn("b")
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		handler.AssertNotLogged(t, LogAttr("n", matchers.Equal(int64(1))))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected no log entry matching (attribute \"n\" equal to 1), but found 1:\n"+
+		"INFO a n=1\ncaptured log output:\nINFO a n=1\nWARN b",
+		result.Failures[0].Message)
+}
@@ -57,6 +57,12 @@ func (m Matcher) Test(value any) (pass bool, failDescription string) {
 	return false, fmt.Sprintf("%s\nfull value was: %s", failureDesc, DescribeValue(value))
 }
 
+// Describe returns a description of the expectation, such as "equal to 3". This can be used to
+// build failure messages in helpers that apply a Matcher to several values.
+func (m Matcher) Describe() string {
+	return m.describeTest()
+}
+
 func (m Matcher) test(value any) bool {
 	if m.testFn == nil {
 		return true
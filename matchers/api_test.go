@@ -46,6 +46,17 @@ func TestSimpleMatcher(t *testing.T) {
 	assertFails(t, "bad", m, `expected: should be good`+"\n"+`full value was: "bad"`)
 }
 
+func TestMatcherDescribe(t *testing.T) {
+	m := New(
+		func(value any) bool { return value == "good" },
+		func() string { return "should be good" },
+		nil,
+	)
+	assert.Equal(t, "should be good", m.Describe())
+	assert.Equal(t, "[no description given for assertion]", Matcher{}.Describe())
+	assert.Equal(t, "length equal to 3", Length().Should(Equal(3)).Describe())
+}
+
 func TestSimpleMatcherWithFailureDescription(t *testing.T) {
 	m := New(
 		func(value any) bool { return value == "good" },
@@ -0,0 +1,68 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrorIs is a matcher for error values that calls errors.Is, so it passes if the value is the
+// target error or wraps it. It fails if the value is not an error.
+func ErrorIs(target error) Matcher {
+	return New(
+		func(value any) bool {
+			err, ok := value.(error)
+			return ok && errors.Is(err, target)
+		},
+		func() string {
+			return fmt.Sprintf("is or wraps error %q", target)
+		},
+		func(value any) string {
+			if _, ok := value.(error); !ok {
+				return fmt.Sprintf("expected an error value, was %T", value)
+			}
+			return fmt.Sprintf("was not and did not wrap error %q", target)
+		},
+	)
+}
+
+// ErrorAs is a matcher for error values that calls errors.As, so it passes if the value is of the
+// error type T or wraps an error of that type. It fails if the value is not an error.
+//
+//	matchers.ErrorAs[*os.PathError]()
+func ErrorAs[T error]() Matcher {
+	typeName := reflect.TypeOf((*T)(nil)).Elem().String()
+	return New(
+		func(value any) bool {
+			err, ok := value.(error)
+			var target T
+			return ok && errors.As(err, &target)
+		},
+		func() string {
+			return fmt.Sprintf("is or wraps an error of type %s", typeName)
+		},
+		func(value any) string {
+			if _, ok := value.(error); !ok {
+				return fmt.Sprintf("expected an error value, was %T", value)
+			}
+			return fmt.Sprintf("was not and did not wrap an error of type %s", typeName)
+		},
+	)
+}
+
+// ErrorMessage is a MatcherTransform that takes an error value and applies some matcher to the
+// result of its Error() method.
+//
+//	matchers.ErrorMessage().Should(matchers.StringContains("not found"))
+func ErrorMessage() MatcherTransform {
+	return Transform(
+		"error message",
+		func(value any) (any, error) {
+			err, ok := value.(error)
+			if !ok {
+				return nil, fmt.Errorf("expected an error value, was %T", value)
+			}
+			return err.Error(), nil
+		},
+	)
+}
@@ -0,0 +1,34 @@
+package matchers
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	myErr := errors.New("sorry")
+	assertPasses(t, myErr, ErrorIs(myErr))
+	assertPasses(t, fmt.Errorf("wrapped: %w", myErr), ErrorIs(myErr))
+	assertFails(t, errors.New("other"), ErrorIs(myErr), `was not and did not wrap error "sorry"`)
+	assertFails(t, "sorry", ErrorIs(myErr), "expected an error value, was string")
+	assertFails(t, nil, ErrorIs(myErr), "expected an error value, was <nil>")
+}
+
+func TestErrorAs(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}
+	assertPasses(t, pathErr, ErrorAs[*fs.PathError]())
+	assertPasses(t, fmt.Errorf("wrapped: %w", pathErr), ErrorAs[*fs.PathError]())
+	assertFails(t, errors.New("other"), ErrorAs[*fs.PathError](),
+		"was not and did not wrap an error of type *fs.PathError")
+	assertFails(t, 3, ErrorAs[*fs.PathError](), "expected an error value, was int")
+}
+
+func TestErrorMessage(t *testing.T) {
+	assertPasses(t, errors.New("not found"), ErrorMessage().Should(StringContains("found")))
+	assertFails(t, errors.New("sorry"), ErrorMessage().Should(StringContains("found")),
+		`error message did not contain "found"`)
+	assertFails(t, "not an error", ErrorMessage().Should(StringContains("found")),
+		"expected an error value, was string")
+}
@@ -0,0 +1,127 @@
+package memfs
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+)
+
+// Operation identifies a kind of file system operation, for use with InjectError.
+type Operation string
+
+const (
+	// OpOpen is FS.Open. Since fs.ReadFile, fs.WalkDir, and similar functions use Open, an error
+	// for this operation also affects them.
+	OpOpen Operation = "open"
+	// OpRead is the Read method of a file returned by FS.Open.
+	OpRead Operation = "read"
+	// OpStat is FS.Stat, or the Stat method of a file returned by FS.Open.
+	OpStat Operation = "stat"
+	// OpReadDir is FS.ReadDir, or the ReadDir method of a directory returned by FS.Open.
+	OpReadDir Operation = "readdir"
+)
+
+type fault struct {
+	pattern    string
+	op         Operation
+	err        error
+	readFaults []helpers.ReaderFault
+}
+
+// InjectError causes the specified operation to fail for every path that matches the pattern. The
+// pattern uses the syntax of path.Match, so it can be either an exact path or something like
+// "flags/*.json". The error is wrapped in an *fs.PathError; typical values are fs.ErrPermission,
+// fs.ErrNotExist, or syscall.EIO.
+//
+//	fsys.InjectError("config.json", memfs.OpOpen, fs.ErrPermission)
+//	fsys.InjectError("data/*", memfs.OpRead, syscall.EIO)
+//
+// This only affects reading through the fs.FS methods, not methods that modify the FS, and it
+// applies to files that are already open as well as ones that are opened later. Use ClearFaults
+// to remove it. InjectError panics if the pattern is malformed.
+func (f *FS) InjectError(pattern string, op Operation, err error) {
+	checkPattern(pattern)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = append(f.faults, fault{pattern: pattern, op: op, err: err})
+}
+
+// InjectReadFaults applies ReaderFaults, such as helpers.ReadFailAfter or helpers.ReadShort, to
+// the content of every file that matches the pattern. This can simulate a partial read:
+//
+//	fsys.InjectReadFaults("config.json", helpers.ReadFailAfter(10, syscall.EIO))
+//
+// The pattern uses the syntax of path.Match. Like InjectError, this applies to files that are
+// already open as well as ones that are opened later; for a file that is already open, the faults
+// start from its current position, so helpers.ReadFailAfter counts the bytes read after that. Use
+// ClearFaults to remove them. InjectReadFaults panics if the pattern is malformed.
+func (f *FS) InjectReadFaults(pattern string, faults ...helpers.ReaderFault) {
+	checkPattern(pattern)
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = append(f.faults, fault{pattern: pattern, readFaults: faults})
+	f.readFaultsVersion++
+}
+
+// ClearFaults removes everything that was set by InjectError and InjectReadFaults. This also
+// affects files that are already open.
+func (f *FS) ClearFaults() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.faults = nil
+	f.readFaultsVersion++
+}
+
+// checkFaultLocked must be called while holding the lock.
+func (f *FS) checkFaultLocked(op Operation, name string) error {
+	for _, flt := range f.faults {
+		if flt.err != nil && flt.op == op {
+			if matched, _ := path.Match(flt.pattern, name); matched {
+				return &fs.PathError{Op: string(op), Path: name, Err: flt.err}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FS) checkFault(op Operation, name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.checkFaultLocked(op, name)
+}
+
+// readFaultsFor must be called while holding the lock.
+func (f *FS) readFaultsFor(name string) []helpers.ReaderFault {
+	var ret []helpers.ReaderFault
+	for _, flt := range f.faults {
+		if matched, _ := path.Match(flt.pattern, name); matched {
+			ret = append(ret, flt.readFaults...)
+		}
+	}
+	return ret
+}
+
+// readerFor returns the reader that the next Read of an open file should use, or an error if the
+// read should fail. If the ReaderFaults have changed since the file was last read, they are applied
+// again to the rest of the file's content.
+func (f *FS) readerFor(o *openFile) (io.Reader, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.checkFaultLocked(OpRead, o.name); err != nil {
+		return nil, err
+	}
+	if o.readFaultsVersion != f.readFaultsVersion {
+		o.reader = helpers.FaultyReader(o.content, f.readFaultsFor(o.name)...)
+		o.readFaultsVersion = f.readFaultsVersion
+	}
+	return o.reader, nil
+}
+
+func checkPattern(pattern string) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(fmt.Errorf("invalid memfs pattern %q: %w", pattern, err))
+	}
+}
@@ -0,0 +1,137 @@
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"syscall"
+	"testing"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectErrorForOpen(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"flags/a.json": "{}", "flags/b.yml": "", "c.json": "{}"}))
+	fsys.InjectError("flags/*.json", OpOpen, fs.ErrPermission)
+
+	_, err := fsys.Open("flags/a.json")
+	require.Error(t, err)
+	assert.Equal(t, "open flags/a.json: permission denied", err.Error())
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+
+	_, err = fs.ReadFile(fsys, "flags/a.json")
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+
+	_, err = fs.ReadFile(fsys, "flags/b.yml")
+	assert.NoError(t, err)
+	_, err = fs.ReadFile(fsys, "c.json")
+	assert.NoError(t, err)
+
+	fsys.ClearFaults()
+	_, err = fs.ReadFile(fsys, "flags/a.json")
+	assert.NoError(t, err)
+}
+
+func TestInjectErrorForRead(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a": "hello"}))
+	file, err := fsys.Open("a")
+	require.NoError(t, err)
+	defer file.Close()
+
+	fsys.InjectError("a", OpRead, syscall.EIO) // affects a file that is already open
+	_, err = file.Read(make([]byte, 5))
+	assert.True(t, errors.Is(err, syscall.EIO))
+
+	fsys.ClearFaults()
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+}
+
+func TestInjectErrorForStat(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a": "hello"}))
+	file, err := fsys.Open("a")
+	require.NoError(t, err)
+	defer file.Close()
+	dir, err := fsys.Open(".")
+	require.NoError(t, err)
+	defer dir.Close()
+
+	fsys.InjectError("*", OpStat, syscall.EIO)
+	_, err = fsys.Stat("a")
+	assert.True(t, errors.Is(err, syscall.EIO))
+	_, err = file.Stat()
+	assert.True(t, errors.Is(err, syscall.EIO))
+	_, err = fsys.Stat(".")
+	assert.True(t, errors.Is(err, syscall.EIO))
+	_, err = dir.Stat()
+	assert.True(t, errors.Is(err, syscall.EIO))
+}
+
+func TestInjectErrorForReadDir(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"sub/a": "hello"}))
+	dir, err := fsys.Open("sub")
+	require.NoError(t, err)
+	defer dir.Close()
+
+	fsys.InjectError("sub", OpReadDir, fs.ErrPermission)
+	_, err = fsys.ReadDir("sub")
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+	_, err = dir.(fs.ReadDirFile).ReadDir(-1)
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+
+	_, err = fsys.ReadDir(".")
+	assert.NoError(t, err)
+}
+
+func TestInjectErrorDoesNotAffectWrites(t *testing.T) {
+	fsys := New(nil)
+	fsys.InjectError("*", OpOpen, fs.ErrPermission)
+	require.NoError(t, fsys.WriteFile("a", []byte("x"), 0))
+	fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a": "x"}))
+}
+
+func TestInjectReadFaults(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a": "hello world"}))
+	fsys.InjectReadFaults("a", helpers.ReadFailAfter(5, syscall.EIO))
+
+	data, err := fs.ReadFile(fsys, "a")
+	assert.Equal(t, "hello", string(data))
+	assert.True(t, errors.Is(err, syscall.EIO))
+
+	fsys.ClearFaults()
+	data, err = fs.ReadFile(fsys, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestInjectReadFaultsAffectsOpenFiles(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a": "hello world"}))
+	file, err := fsys.Open("a")
+	require.NoError(t, err)
+	defer file.Close()
+
+	buf := make([]byte, 3)
+	_, err = io.ReadFull(file, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hel", string(buf))
+
+	fsys.InjectReadFaults("a", helpers.ReadFailAfter(2, syscall.EIO)) // counts from the current position
+	n, err := io.ReadFull(file, buf)
+	assert.Equal(t, "lo", string(buf[:n]))
+	assert.True(t, errors.Is(err, syscall.EIO))
+
+	fsys.ClearFaults()
+	data, err := io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, " world", string(data))
+}
+
+func TestInjectWithInvalidPattern(t *testing.T) {
+	fsys := New(nil)
+	assert.Panics(t, func() { fsys.InjectError("[", OpOpen, fs.ErrPermission) })
+	assert.Panics(t, func() { fsys.InjectReadFaults("[", helpers.ReadShort(1)) })
+}
@@ -0,0 +1,271 @@
+package memfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+)
+
+const (
+	defaultFileMode = 0644
+	defaultDirMode  = 0755
+)
+
+// FS is an in-memory file system that implements fs.FS, fs.ReadDirFS, and fs.StatFS. Create one
+// with New.
+//
+// Paths use the same rules as fs.FS: they are slash-separated, relative to the root, and must
+// satisfy fs.ValidPath. The root directory is ".". As on disk, a method that adds, replaces, or
+// removes something in a directory also sets the directory's modification time to the current time.
+type FS struct {
+	nodes  map[string]*node
+	faults []fault
+	// readFaultsVersion changes whenever the faults change in a way that could affect ReaderFaults,
+	// so that open files know to reapply them.
+	readFaultsVersion int
+	lock              sync.Mutex
+}
+
+type node struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+// New creates an FS containing the files and directories described by the DirTree, which can be
+// nil. Symbolic links are not supported. New panics if the DirTree contains an invalid path or a
+// symbolic link, since that is a mistake in the test code.
+func New(tree helpers.DirTree) *FS {
+	f := &FS{nodes: map[string]*node{".": {mode: fs.ModeDir | defaultDirMode, modTime: time.Now()}}}
+	paths := make([]string, 0, len(tree))
+	for p := range tree {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		entry := tree[p]
+		var err error
+		switch {
+		case entry.LinkTarget != "":
+			err = fmt.Errorf("symbolic links are not supported: %q", p)
+		case entry.Mode.IsDir():
+			err = f.MkdirAll(p, entry.Mode.Perm())
+		default:
+			err = f.WriteFile(p, []byte(entry.Content), entry.Mode.Perm())
+		}
+		if err != nil {
+			panic(fmt.Errorf("memfs.New: %w", err))
+		}
+	}
+	return f
+}
+
+// Open opens the named file or directory for reading. The returned fs.File reads from a snapshot
+// of the file's content at the time it was opened, so later changes to the FS do not affect it.
+// Directories implement fs.ReadDirFile.
+func (f *FS) Open(name string) (fs.File, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.checkFaultLocked(OpOpen, name); err != nil {
+		return nil, err
+	}
+	n, err := f.getNode("open", name)
+	if err != nil {
+		return nil, err
+	}
+	info := n.info(name)
+	if n.mode.IsDir() {
+		entries, err := f.readDir(name)
+		if err != nil {
+			return nil, err
+		}
+		return &openDir{fsys: f, name: name, info: info, entries: entries}, nil
+	}
+	content := bytes.NewReader(n.data)
+	return &openFile{
+		fsys:              f,
+		name:              name,
+		info:              info,
+		content:           content,
+		reader:            helpers.FaultyReader(content, f.readFaultsFor(name)...),
+		readFaultsVersion: f.readFaultsVersion,
+	}, nil
+}
+
+// ReadDir reads the named directory and returns its entries sorted by name.
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.checkFaultLocked(OpReadDir, name); err != nil {
+		return nil, err
+	}
+	return f.readDir(name)
+}
+
+// Stat returns information about the named file or directory.
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := f.checkFaultLocked(OpStat, name); err != nil {
+		return nil, err
+	}
+	n, err := f.getNode("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return n.info(name), nil
+}
+
+// getNode must be called while holding the lock.
+func (f *FS) getNode(op, name string) (*node, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	n, ok := f.nodes[name]
+	if !ok {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return n, nil
+}
+
+// readDir must be called while holding the lock.
+func (f *FS) readDir(name string) ([]fs.DirEntry, error) {
+	n, err := f.getNode("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if !n.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	var entries []fs.DirEntry
+	for p, child := range f.nodes {
+		if p != "." && path.Dir(p) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(child.info(p)))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+func (n *node) info(name string) fs.FileInfo {
+	return fileInfo{name: path.Base(name), size: int64(len(n.data)), mode: n.mode, modTime: n.modTime}
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fileInfo) ModTime() time.Time { return i.modTime }
+func (i fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fileInfo) Sys() any           { return nil }
+
+type openFile struct {
+	fsys              *FS
+	name              string
+	info              fs.FileInfo
+	content           *bytes.Reader
+	reader            io.Reader // content with any ReaderFaults applied
+	readFaultsVersion int
+	closed            bool
+}
+
+func (o *openFile) Stat() (fs.FileInfo, error) {
+	if o.closed {
+		return nil, &fs.PathError{Op: "stat", Path: o.name, Err: fs.ErrClosed}
+	}
+	if err := o.fsys.checkFault(OpStat, o.name); err != nil {
+		return nil, err
+	}
+	return o.info, nil
+}
+
+func (o *openFile) Read(p []byte) (int, error) {
+	if o.closed {
+		return 0, &fs.PathError{Op: "read", Path: o.name, Err: fs.ErrClosed}
+	}
+	reader, err := o.fsys.readerFor(o)
+	if err != nil {
+		return 0, err
+	}
+	return reader.Read(p)
+}
+
+func (o *openFile) Close() error {
+	if o.closed {
+		return &fs.PathError{Op: "close", Path: o.name, Err: fs.ErrClosed}
+	}
+	o.closed = true
+	return nil
+}
+
+type openDir struct {
+	fsys    *FS
+	name    string
+	info    fs.FileInfo
+	entries []fs.DirEntry
+	offset  int
+	closed  bool
+}
+
+func (o *openDir) Stat() (fs.FileInfo, error) {
+	if o.closed {
+		return nil, &fs.PathError{Op: "stat", Path: o.name, Err: fs.ErrClosed}
+	}
+	if err := o.fsys.checkFault(OpStat, o.name); err != nil {
+		return nil, err
+	}
+	return o.info, nil
+}
+
+func (o *openDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: o.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir follows the contract of fs.ReadDirFile: if n > 0, it returns at most n entries and
+// returns io.EOF at the end; otherwise, it returns all the remaining entries.
+func (o *openDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if o.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: o.name, Err: fs.ErrClosed}
+	}
+	if err := o.fsys.checkFault(OpReadDir, o.name); err != nil {
+		return nil, err
+	}
+	remaining := o.entries[o.offset:]
+	if n <= 0 {
+		o.offset = len(o.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	count := min(n, len(remaining))
+	o.offset += count
+	return remaining[:count], nil
+}
+
+func (o *openDir) Close() error {
+	if o.closed {
+		return &fs.PathError{Op: "close", Path: o.name, Err: fs.ErrClosed}
+	}
+	o.closed = true
+	return nil
+}
+
+// isUnder returns true if p is the same as dir or is inside it.
+func isUnder(p, dir string) bool {
+	return dir == "." || p == dir || strings.HasPrefix(p, dir+"/")
+}
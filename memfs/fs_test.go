@@ -0,0 +1,151 @@
+package memfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSConformsToFSInterface(t *testing.T) {
+	fsys := New(helpers.DirTree{
+		"a.txt":         {Content: "hello"},
+		"sub/b.json":    {Content: `{"b": 1}`, Mode: 0600},
+		"sub/deep/c":    {Content: ""},
+		"emptydir":      {Mode: fs.ModeDir | 0700},
+		"sub/deep/more": {Content: "x"},
+	})
+	require.NoError(t, fstest.TestFS(fsys, "a.txt", "sub/b.json", "sub/deep/c", "sub/deep/more", "emptydir"))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("nil tree", func(t *testing.T) {
+		fsys := New(nil)
+		entries, err := fsys.ReadDir(".")
+		require.NoError(t, err)
+		assert.Len(t, entries, 0)
+	})
+
+	t.Run("modes", func(t *testing.T) {
+		fsys := New(helpers.DirTree{
+			"a":     {Content: "x"},
+			"b":     {Content: "y", Mode: 0600},
+			"c/d":   {Content: "z"},
+			"empty": {Mode: fs.ModeDir | 0700},
+		})
+		for name, mode := range map[string]fs.FileMode{
+			".":     fs.ModeDir | 0755,
+			"a":     0644,
+			"b":     0600,
+			"c":     fs.ModeDir | 0755,
+			"empty": fs.ModeDir | 0700,
+		} {
+			info, err := fsys.Stat(name)
+			require.NoError(t, err, name)
+			assert.Equal(t, mode, info.Mode(), name)
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		assert.Panics(t, func() { New(helpers.DirTreeOf(map[string]string{"../a": "x"})) })
+	})
+
+	t.Run("symbolic link", func(t *testing.T) {
+		assert.Panics(t, func() { New(helpers.DirTree{"a": {LinkTarget: "b"}}) })
+	})
+}
+
+func TestOpenAndRead(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"sub/a.txt": "hello"}))
+
+	data, err := fs.ReadFile(fsys, "sub/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	file, err := fsys.Open("sub/a.txt")
+	require.NoError(t, err)
+	require.NoError(t, fsys.WriteFile("sub/a.txt", []byte("changed"), 0))
+	data, err = io.ReadAll(file)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data)) // an open file is not affected by later changes
+	require.NoError(t, file.Close())
+
+	_, err = file.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, fs.ErrClosed))
+	assert.True(t, errors.Is(file.Close(), fs.ErrClosed))
+
+	_, err = fsys.Open("sub/b.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	_, err = fsys.Open("/sub/a.txt")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+}
+
+func TestOpenDirectory(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"sub/a": "1", "sub/b": "2", "sub/c/d": "3"}))
+
+	file, err := fsys.Open("sub")
+	require.NoError(t, err)
+	defer file.Close()
+	dir, ok := file.(fs.ReadDirFile)
+	require.True(t, ok)
+
+	_, err = dir.Read(make([]byte, 1))
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+
+	entries, err := dir.ReadDir(2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Name())
+	assert.Equal(t, "b", entries[1].Name())
+
+	entries, err = dir.ReadDir(2)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "c", entries[0].Name())
+	assert.True(t, entries[0].IsDir())
+
+	_, err = dir.ReadDir(2)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestStat(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"sub/a.txt": "hello"}))
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, fsys.SetModTime("sub/a.txt", modTime))
+
+	info, err := fsys.Stat("sub/a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "a.txt", info.Name())
+	assert.Equal(t, int64(5), info.Size())
+	assert.Equal(t, modTime, info.ModTime())
+	assert.False(t, info.IsDir())
+	assert.Nil(t, info.Sys())
+
+	_, err = fsys.Stat("sub/b.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.True(t, errors.Is(fsys.SetModTime("sub/b.txt", modTime), fs.ErrNotExist))
+}
+
+func TestReadDir(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"b": "", "a/x": "", "c": ""}))
+
+	entries, err := fsys.ReadDir(".")
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+
+	_, err = fsys.ReadDir("b")
+	assert.True(t, errors.Is(err, fs.ErrInvalid))
+	_, err = fsys.ReadDir("d")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
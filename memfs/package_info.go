@@ -0,0 +1,19 @@
+// Package memfs provides a mutable in-memory file system, for testing code that reads files through
+// an fs.FS. Unlike a temporary directory, it can be made to return errors from specific operations
+// on specific files.
+//
+//	fsys := memfs.New(helpers.DirTreeOf(map[string]string{
+//	    "flags/flag1.json": `{"key": "flag1"}`,
+//	}))
+//	source := NewFileDataSource(fsys)
+//
+//	fsys.InjectError("flags/*.json", memfs.OpOpen, fs.ErrPermission)
+//	// ... source should report an error
+//
+//	fsys.ClearFaults()
+//	_ = fsys.WriteFile("flags/flag2.json.tmp", []byte(`{"key": "flag2"}`), 0644)
+//	_ = fsys.Rename("flags/flag2.json.tmp", "flags/flag2.json")
+//	// ... source should pick up the new file
+//
+// An FS is safe for concurrent use.
+package memfs
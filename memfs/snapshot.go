@@ -0,0 +1,52 @@
+package memfs
+
+import (
+	"path"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Snapshot returns the current contents of the FS as a DirTree, in the same format as
+// helpers.ReadDirTree: directories are only included if they are empty. This is not affected by
+// InjectError or InjectReadFaults.
+//
+// The result can be passed to helpers.WriteDirTree or helpers.TempDirTree to copy the contents to
+// a real directory, or to New to copy them to another FS.
+func (f *FS) Snapshot() helpers.DirTree {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	nonEmptyDirs := make(map[string]bool)
+	for p := range f.nodes {
+		if p != "." {
+			nonEmptyDirs[path.Dir(p)] = true
+		}
+	}
+	ret := make(helpers.DirTree)
+	for p, n := range f.nodes {
+		switch {
+		case p == ".":
+		case n.mode.IsDir():
+			if !nonEmptyDirs[p] {
+				ret[p] = helpers.DirTreeEntry{Mode: n.mode}
+			}
+		default:
+			ret[p] = helpers.DirTreeEntry{Content: string(n.data), Mode: n.mode}
+		}
+	}
+	return ret
+}
+
+// AssertMatches asserts that the contents of the FS are the same as the specified DirTree. This is
+// the equivalent of helpers.AssertDirMatches for an FS, and reports differences in the same way.
+//
+//	fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{
+//	    "config.json": `{"a": 2}`,
+//	}))
+func (f *FS) AssertMatches(t assert.TestingT, expected helpers.DirTree, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return helpers.AssertDirTreeMatches(t, f.Snapshot(), expected, customMessageAndArgs...)
+}
@@ -0,0 +1,56 @@
+package memfs
+
+import (
+	"io/fs"
+	"testing"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	fsys := New(helpers.DirTree{
+		"a.txt":    {Content: "hello"},
+		"sub/b":    {Content: "x", Mode: 0600},
+		"emptydir": {Mode: fs.ModeDir | 0700},
+	})
+	fsys.InjectError("*", OpOpen, fs.ErrPermission)
+
+	assert.Equal(t, helpers.DirTree{
+		"a.txt":    {Content: "hello", Mode: 0644},
+		"sub/b":    {Content: "x", Mode: 0600},
+		"emptydir": {Mode: fs.ModeDir | 0700},
+	}, fsys.Snapshot())
+}
+
+func TestSnapshotCanBeWrittenToDisk(t *testing.T) {
+	tree := helpers.DirTree{"a.txt": {Content: "hello"}, "sub/b": {Content: "x", Mode: 0600}}
+	fsys := New(tree)
+	helpers.WithTempDirTree(fsys.Snapshot(), func(root string) {
+		helpers.AssertDirMatches(t, root, tree)
+	})
+}
+
+func TestAssertMatches(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a.json": `{"a": 1}`, "b": "x"}))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a.json": `{ "a":1 }`, "b": "x"}))
+	})
+	assert.False(t, result.Failed)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a.json": `{"a": 2}`, "c": "y"}), "custom %s", "message")
+	})
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "directory tree did not have the expected contents:\n"+
+		"a.json: content differs\n"+
+		`at "a": expected = 2, actual = 1`+"\n"+
+		"missing: c\n"+
+		"unexpected: b",
+		result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+}
@@ -0,0 +1,187 @@
+package memfs
+
+import (
+	"io/fs"
+	"path"
+	"time"
+)
+
+// WriteFile creates or replaces the named file, creating any missing parent directories. If perm
+// is zero, the permissions are 0644 for a new file, or unchanged for an existing one. The file's
+// modification time is set to the current time.
+//
+// Like all of the methods that modify the FS, this is not affected by InjectError.
+func (f *FS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := checkWritablePath("write", name); err != nil {
+		return err
+	}
+	existing, exists := f.nodes[name]
+	if exists && existing.mode.IsDir() {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrExist}
+	}
+	if err := f.mkdirAllLocked("write", path.Dir(name), 0); err != nil {
+		return err
+	}
+	switch {
+	case perm.Perm() != 0:
+		perm = perm.Perm()
+	case exists:
+		perm = existing.mode.Perm()
+	default:
+		perm = defaultFileMode
+	}
+	f.nodes[name] = &node{data: append([]byte(nil), data...), mode: perm, modTime: time.Now()}
+	f.touchParentLocked(name)
+	return nil
+}
+
+// MkdirAll creates the named directory, along with any missing parent directories. If perm is
+// zero, the permissions of new directories are 0755. It does nothing if the directory already
+// exists.
+func (f *FS) MkdirAll(name string, perm fs.FileMode) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.mkdirAllLocked("mkdir", name, perm)
+}
+
+// Remove removes the named file or empty directory.
+func (f *FS) Remove(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := checkWritablePath("remove", name); err != nil {
+		return err
+	}
+	n, err := f.getNode("remove", name)
+	if err != nil {
+		return err
+	}
+	if n.mode.IsDir() {
+		for p := range f.nodes {
+			if p != name && isUnder(p, name) {
+				return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrExist}
+			}
+		}
+	}
+	delete(f.nodes, name)
+	f.touchParentLocked(name)
+	return nil
+}
+
+// RemoveAll removes the named file, or the named directory and everything in it. It does nothing
+// if the path does not exist.
+func (f *FS) RemoveAll(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if err := checkWritablePath("remove", name); err != nil {
+		return err
+	}
+	if _, exists := f.nodes[name]; !exists {
+		return nil
+	}
+	for p := range f.nodes {
+		if isUnder(p, name) {
+			delete(f.nodes, p)
+		}
+	}
+	f.touchParentLocked(name)
+	return nil
+}
+
+// Rename moves a file or directory, replacing newName if it is an existing file. This happens
+// atomically, as it would for os.Rename on a Unix-like system, so it can be used to simulate a
+// configuration file being replaced while the code under test is watching it:
+//
+//	_ = fsys.WriteFile("config.json.tmp", newContent, 0)
+//	_ = fsys.Rename("config.json.tmp", "config.json")
+//
+// As with os.Rename, the parent directory of newName must already exist.
+func (f *FS) Rename(oldName, newName string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	for _, p := range []string{oldName, newName} {
+		if err := checkWritablePath("rename", p); err != nil {
+			return err
+		}
+	}
+	n, err := f.getNode("rename", oldName)
+	if err != nil {
+		return err
+	}
+	if parent, ok := f.nodes[path.Dir(newName)]; !ok || !parent.mode.IsDir() {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrNotExist}
+	}
+	if oldName == newName {
+		return nil
+	}
+	if existing, ok := f.nodes[newName]; ok && (existing.mode.IsDir() || n.mode.IsDir()) {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrExist}
+	}
+	if n.mode.IsDir() && isUnder(newName, oldName) {
+		return &fs.PathError{Op: "rename", Path: newName, Err: fs.ErrInvalid}
+	}
+	moved := make(map[string]*node)
+	for p, child := range f.nodes {
+		if isUnder(p, oldName) {
+			moved[newName+p[len(oldName):]] = child
+			delete(f.nodes, p)
+		}
+	}
+	for p, child := range moved {
+		f.nodes[p] = child
+	}
+	f.touchParentLocked(oldName)
+	f.touchParentLocked(newName)
+	return nil
+}
+
+// SetModTime changes the modification time of the named file or directory.
+func (f *FS) SetModTime(name string, modTime time.Time) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	n, err := f.getNode("chtimes", name)
+	if err != nil {
+		return err
+	}
+	n.modTime = modTime
+	return nil
+}
+
+// mkdirAllLocked must be called while holding the lock.
+func (f *FS) mkdirAllLocked(op, name string, perm fs.FileMode) error {
+	if n, ok := f.nodes[name]; ok {
+		if n.mode.IsDir() {
+			return nil
+		}
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrExist}
+	}
+	if err := f.mkdirAllLocked(op, path.Dir(name), 0); err != nil {
+		return err
+	}
+	if perm.Perm() == 0 {
+		perm = defaultDirMode
+	}
+	f.nodes[name] = &node{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	f.touchParentLocked(name)
+	return nil
+}
+
+// touchParentLocked sets the modification time of the directory containing name to the current
+// time. It must be called while holding the lock.
+func (f *FS) touchParentLocked(name string) {
+	if parent, ok := f.nodes[path.Dir(name)]; ok {
+		parent.modTime = time.Now()
+	}
+}
+
+// checkWritablePath returns an error if the path is invalid or is the root directory.
+func checkWritablePath(op, name string) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
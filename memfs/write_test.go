@@ -0,0 +1,158 @@
+package memfs
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	fsys := New(nil)
+
+	before := time.Now()
+	require.NoError(t, fsys.WriteFile("a/b/c.txt", []byte("hello"), 0))
+	info, err := fsys.Stat("a/b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0644), info.Mode())
+	assert.False(t, info.ModTime().Before(before))
+
+	require.NoError(t, fsys.WriteFile("a/b/c.txt", []byte("bye"), 0600))
+	require.NoError(t, fsys.WriteFile("a/b/c.txt", []byte("bye!"), 0))
+	info, err = fsys.Stat("a/b/c.txt")
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0600), info.Mode()) // unchanged by a write with perm 0
+
+	fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a/b/c.txt": "bye!"}))
+
+	assert.True(t, errors.Is(fsys.WriteFile("a/b", nil, 0), fs.ErrExist))
+	assert.True(t, errors.Is(fsys.WriteFile("a/b/c.txt/d", nil, 0), fs.ErrExist))
+	assert.True(t, errors.Is(fsys.WriteFile(".", nil, 0), fs.ErrInvalid))
+	assert.True(t, errors.Is(fsys.WriteFile("../x", nil, 0), fs.ErrInvalid))
+}
+
+func TestWriteFileCopiesData(t *testing.T) {
+	fsys := New(nil)
+	data := []byte("hello")
+	require.NoError(t, fsys.WriteFile("a", data, 0))
+	data[0] = 'j'
+	fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a": "hello"}))
+}
+
+func TestMkdirAll(t *testing.T) {
+	fsys := New(nil)
+	require.NoError(t, fsys.MkdirAll("a/b", 0700))
+	require.NoError(t, fsys.MkdirAll("a/b", 0)) // already exists
+
+	info, err := fsys.Stat("a")
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModeDir|0755, info.Mode())
+	info, err = fsys.Stat("a/b")
+	require.NoError(t, err)
+	assert.Equal(t, fs.ModeDir|0700, info.Mode())
+
+	require.NoError(t, fsys.WriteFile("f", nil, 0))
+	assert.True(t, errors.Is(fsys.MkdirAll("f/g", 0), fs.ErrExist))
+	assert.True(t, errors.Is(fsys.MkdirAll("/a", 0), fs.ErrInvalid))
+}
+
+func TestRemove(t *testing.T) {
+	fsys := New(helpers.DirTree{"a/b": {Content: "x"}, "c": {Mode: fs.ModeDir}})
+
+	assert.True(t, errors.Is(fsys.Remove("a"), fs.ErrExist)) // not empty
+	require.NoError(t, fsys.Remove("a/b"))
+	require.NoError(t, fsys.Remove("a"))
+	require.NoError(t, fsys.Remove("c"))
+	assert.True(t, errors.Is(fsys.Remove("c"), fs.ErrNotExist))
+	assert.True(t, errors.Is(fsys.Remove("."), fs.ErrInvalid))
+	fsys.AssertMatches(t, helpers.DirTree{})
+}
+
+func TestRemoveAll(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a/b/c": "x", "a/d": "y", "ab": "z"}))
+
+	require.NoError(t, fsys.RemoveAll("a"))
+	require.NoError(t, fsys.RemoveAll("nonexistent"))
+	fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"ab": "z"}))
+}
+
+func TestRename(t *testing.T) {
+	t.Run("file", func(t *testing.T) {
+		fsys := New(helpers.DirTreeOf(map[string]string{"config.json": "old", "config.json.tmp": "new"}))
+		require.NoError(t, fsys.Rename("config.json.tmp", "config.json"))
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"config.json": "new"}))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		fsys := New(helpers.DirTreeOf(map[string]string{"a/b/c": "x", "a/d": "y", "e/f": "z"}))
+		require.NoError(t, fsys.Rename("a", "e/g"))
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"e/g/b/c": "x", "e/g/d": "y", "e/f": "z"}))
+	})
+
+	t.Run("same name", func(t *testing.T) {
+		fsys := New(helpers.DirTreeOf(map[string]string{"a": "x"}))
+		require.NoError(t, fsys.Rename("a", "a"))
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a": "x"}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		fsys := New(helpers.DirTreeOf(map[string]string{"a/b": "x", "c/d": "y", "e": "z"}))
+		assert.True(t, errors.Is(fsys.Rename("nonexistent", "f"), fs.ErrNotExist))
+		assert.True(t, errors.Is(fsys.Rename("e", "f/g"), fs.ErrNotExist)) // parent does not exist
+		assert.True(t, errors.Is(fsys.Rename("e", "a"), fs.ErrExist))      // can't replace a directory
+		assert.True(t, errors.Is(fsys.Rename("a", "e"), fs.ErrExist))      // can't replace a file with a directory
+		assert.True(t, errors.Is(fsys.Rename("a", "a/x"), fs.ErrInvalid))  // can't move a directory into itself
+		assert.True(t, errors.Is(fsys.Rename(".", "x"), fs.ErrInvalid))
+		fsys.AssertMatches(t, helpers.DirTreeOf(map[string]string{"a/b": "x", "c/d": "y", "e": "z"}))
+	})
+}
+
+func TestChangesUpdateParentModTime(t *testing.T) {
+	fsys := New(helpers.DirTreeOf(map[string]string{"a/x": "1", "b/y": "2", "b/sub/z": "3"}))
+	oldTime := time.Now().Add(-time.Hour)
+	resetModTimes := func() {
+		for _, dir := range []string{".", "a", "b"} {
+			require.NoError(t, fsys.SetModTime(dir, oldTime))
+		}
+	}
+	assertModified := func(dir string, expected bool) {
+		t.Helper()
+		info, err := fsys.Stat(dir)
+		require.NoError(t, err)
+		assert.Equal(t, expected, info.ModTime().After(oldTime), "modification time of %q", dir)
+	}
+
+	resetModTimes()
+	require.NoError(t, fsys.WriteFile("a/x", []byte("new"), 0))
+	assertModified("a", true)
+	assertModified(".", false)
+
+	resetModTimes()
+	require.NoError(t, fsys.MkdirAll("a/c/d", 0))
+	assertModified("a", true)
+	assertModified(".", false)
+
+	resetModTimes()
+	require.NoError(t, fsys.Remove("a/x"))
+	assertModified("a", true)
+
+	resetModTimes()
+	require.NoError(t, fsys.RemoveAll("b/sub"))
+	assertModified("b", true)
+	assertModified(".", false)
+
+	resetModTimes()
+	require.NoError(t, fsys.RemoveAll("b/nonexistent"))
+	assertModified("b", false)
+
+	resetModTimes()
+	require.NoError(t, fsys.Rename("b/y", "a/y"))
+	assertModified("a", true)
+	assertModified("b", true)
+	assertModified(".", false)
+}
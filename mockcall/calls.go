@@ -0,0 +1,299 @@
+package mockcall
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// This is shared by all Calls instances, so that AssertOrder can compare calls to different mocks.
+//
+//nolint:gochecknoglobals // the ordering of calls needs to be process-wide
+var lastSequenceNumber atomic.Uint64
+
+// Call is a recorded call.
+type Call[Args, Result any] struct {
+	// Name is the name of the Calls that recorded this call.
+	Name string
+	// Args is the parameter value that was passed to Calls.Call.
+	Args Args
+	// Result is the value that Calls.Call returned.
+	Result Result
+
+	sequenceNumber uint64
+}
+
+// String returns a description of the call in the format "name(args)", where the args are
+// formatted with matchers.DescribeValue.
+func (c Call[Args, Result]) String() string {
+	return fmt.Sprintf("%s(%s)", c.Name, matchers.DescribeValue(c.Args))
+}
+
+// Calls records calls to one method of a fake implementation, and returns programmed results for
+// them. Args is the type of the method's parameters, and Result is the type of its results; for
+// methods with more than one of either, use a struct type.
+//
+// Results are chosen as follows: first, any results that were queued with ReturnsOnce, in order;
+// then, the result of the first rule added with ReturnsWhen whose matcher matches the args; then,
+// the result set with Returns or ReturnsFunc, if any; otherwise, the zero value of Result.
+//
+// A Calls is safe for concurrent use.
+type Calls[Args, Result any] struct {
+	name     string
+	recorder *helpers.Recorder[Call[Args, Result]]
+	queued   []Result
+	rules    []resultRule[Args, Result]
+	fallback func(Args) Result
+	lock     sync.Mutex
+}
+
+type resultRule[Args, Result any] struct {
+	matcher matchers.Matcher
+	result  Result
+}
+
+// New creates a Calls. The name is used in failure messages, so it should identify the method,
+// such as "Store.Get".
+func New[Args, Result any](name string) *Calls[Args, Result] {
+	return &Calls[Args, Result]{name: name, recorder: helpers.NewRecorder[Call[Args, Result]]()}
+}
+
+// Name returns the name that was passed to New.
+func (c *Calls[Args, Result]) Name() string {
+	return c.name
+}
+
+// Returns sets the result for all calls that do not have a more specific result, replacing any
+// previous Returns or ReturnsFunc. It returns the same Calls, so it can be chained after New.
+//
+//	get := mockcall.New[string, int]("Get").Returns(3)
+func (c *Calls[Args, Result]) Returns(result Result) *Calls[Args, Result] {
+	return c.ReturnsFunc(func(Args) Result { return result })
+}
+
+// ReturnsFunc sets a function that computes the result for all calls that do not have a more
+// specific result, replacing any previous Returns or ReturnsFunc. The function is called without
+// holding any lock, so it can call methods of the same Calls; a call is recorded after the function
+// returns, so any calls that the function makes are recorded before it.
+func (c *Calls[Args, Result]) ReturnsFunc(fn func(Args) Result) *Calls[Args, Result] {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.fallback = fn
+	return c
+}
+
+// ReturnsOnce queues results for the next calls, one result per call, in order. These take
+// precedence over any other results.
+func (c *Calls[Args, Result]) ReturnsOnce(results ...Result) *Calls[Args, Result] {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queued = append(c.queued, results...)
+	return c
+}
+
+// ReturnsWhen adds a rule that returns the specified result for calls whose args match the matcher.
+// If several rules match, the first one that was added is used.
+func (c *Calls[Args, Result]) ReturnsWhen(argsMatcher matchers.Matcher, result Result) *Calls[Args, Result] {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.rules = append(c.rules, resultRule[Args, Result]{matcher: argsMatcher, result: result})
+	return c
+}
+
+// Call records a call with the specified args, and returns the programmed result. The fake
+// implementation's method should call this.
+func (c *Calls[Args, Result]) Call(args Args) Result {
+	c.lock.Lock()
+	result, fallback := c.chooseResult(args)
+	c.lock.Unlock()
+	if fallback != nil {
+		result = fallback(args)
+	}
+	c.lock.Lock()
+	// The sequence number must be assigned in the same order that calls are added to the recorder
+	call := Call[Args, Result]{Name: c.name, Args: args, Result: result, sequenceNumber: lastSequenceNumber.Add(1)}
+	c.recorder.Add(call)
+	c.lock.Unlock()
+	return result
+}
+
+// chooseResult must be called while holding the lock. If the result should come from the function
+// set by ReturnsFunc, it returns that function instead, so that the caller can call it after
+// releasing the lock.
+func (c *Calls[Args, Result]) chooseResult(args Args) (Result, func(Args) Result) {
+	if len(c.queued) != 0 {
+		result := c.queued[0]
+		c.queued = c.queued[1:]
+		return result, nil
+	}
+	for _, r := range c.rules {
+		if pass, _ := r.matcher.Test(args); pass {
+			return r.result, nil
+		}
+	}
+	var empty Result
+	return empty, c.fallback
+}
+
+// All returns all of the calls that have been made so far, in order.
+func (c *Calls[Args, Result]) All() []Call[Args, Result] {
+	return c.recorder.Snapshot()
+}
+
+// Count returns the number of calls that have been made so far.
+func (c *Calls[Args, Result]) Count() int {
+	return c.recorder.Len()
+}
+
+// Chan returns a channel that receives every call, in order, including calls that were made
+// before Chan was first called. This allows the calls to be consumed with channel helpers such as
+// helpers.TryReceive. Every call to Chan returns the same channel.
+//
+// Calls that the test has not read yet are held in memory by a background goroutine, which keeps
+// running until they have all been read or Close is called.
+func (c *Calls[Args, Result]) Chan() <-chan Call[Args, Result] {
+	return c.recorder.Chan()
+}
+
+// Close stops delivering calls to the channel returned by Chan, and closes the channel. Calls that
+// have not been read from the channel yet are discarded. Calls can still be made after Close, and
+// are still counted by Count, but are no longer delivered to the channel.
+//
+// It is only necessary to call Close if a test uses Chan but may not read every call from it, since
+// otherwise the background goroutine that delivers unread calls would be left running.
+//
+//	c := mockcall.New[string, int]("Store.Get")
+//	defer c.Close()
+func (c *Calls[Args, Result]) Close() {
+	c.recorder.Close()
+}
+
+// RequireCall returns the next call from Chan, or causes the test to fail and stop immediately if
+// there is no call before the timeout. This has the same behavior as helpers.RequireValue.
+func (c *Calls[Args, Result]) RequireCall(
+	t require.TestingT,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) Call[Args, Result] {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return helpers.RequireValue(t, c.Chan(), timeout, customMessageAndArgs...)
+}
+
+// RequireCallMatching consumes calls from Chan until it gets one whose args match the matcher, and
+// returns that call. If there is no such call before the timeout, it causes the test to fail and
+// stop immediately, describing the calls that did not match. This has the same behavior as
+// helpers.RequireValueMatching.
+func (c *Calls[Args, Result]) RequireCallMatching(
+	t require.TestingT,
+	argsMatcher matchers.Matcher,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) Call[Args, Result] {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return helpers.RequireValueMatching(t, c.Chan(), callArgs[Args, Result]().Should(argsMatcher), timeout,
+		customMessageAndArgs...)
+}
+
+// AssertCount asserts that exactly the specified number of calls have been made so far. On failure,
+// it lists the calls.
+func (c *Calls[Args, Result]) AssertCount(t assert.TestingT, expected int, customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	all := c.All()
+	if len(all) == expected {
+		return true
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs, "expected %d call(s) to %s, but there were %d%s",
+		expected, c.name, len(all), describeCalls(all))
+	return false
+}
+
+// AssertCalled asserts that at least one call has been made so far whose args match the matcher.
+// On failure, it lists the calls.
+func (c *Calls[Args, Result]) AssertCalled(
+	t assert.TestingT,
+	argsMatcher matchers.Matcher,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	all := c.All()
+	for _, call := range all {
+		if pass, _ := argsMatcher.Test(call.Args); pass {
+			return true
+		}
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs, "expected a call to %s with args (%s), but there was none%s",
+		c.name, argsMatcher.Describe(), describeCalls(all))
+	return false
+}
+
+// AssertNotCalled asserts that no call has been made so far whose args match the matcher. On
+// failure, it lists the calls that matched.
+func (c *Calls[Args, Result]) AssertNotCalled(
+	t assert.TestingT,
+	argsMatcher matchers.Matcher,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	var found []Call[Args, Result]
+	for _, call := range c.All() {
+		if pass, _ := argsMatcher.Test(call.Args); pass {
+			found = append(found, call)
+		}
+	}
+	if len(found) == 0 {
+		return true
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs, "expected no call to %s with args (%s), but there were %d%s",
+		c.name, argsMatcher.Describe(), len(found), describeCalls(found))
+	return false
+}
+
+func callArgs[Args, Result any]() matchers.MatcherTransform {
+	return matchers.Transform("args", func(value any) (any, error) {
+		return value.(Call[Args, Result]).Args, nil
+	}).EnsureInputValueType(Call[Args, Result]{})
+}
+
+func describeCalls[Args, Result any](calls []Call[Args, Result]) string {
+	if len(calls) == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(calls))
+	for _, call := range calls {
+		lines = append(lines, call.String())
+	}
+	return ":\n" + strings.Join(lines, "\n")
+}
+
+// failWithMessageAndArgs follows the same conventions as the helpers package: the custom message,
+// if any, is reported separately, and any helpers.FailureOption values are not part of it.
+func failWithMessageAndArgs(t assert.TestingT, customMessageAndArgs []any, defaultMsg string, defaultArgs ...any) {
+	t.Errorf(defaultMsg, defaultArgs...)
+	var custom []any
+	for _, a := range customMessageAndArgs {
+		if _, ok := a.(helpers.FailureOption); !ok {
+			custom = append(custom, a)
+		}
+	}
+	if len(custom) != 0 {
+		t.Errorf(fmt.Sprintf("%s", custom[0]), custom[1:]...)
+	}
+}
@@ -0,0 +1,195 @@
+package mockcall
+
+import (
+	"testing"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testArgs struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+func TestCallRecordsCalls(t *testing.T) {
+	c := New[string, int]("Get")
+	assert.Equal(t, "Get", c.Name())
+	assert.Equal(t, 0, c.Count())
+
+	assert.Equal(t, 0, c.Call("a"))
+	assert.Equal(t, 0, c.Call("b"))
+	assert.Equal(t, 2, c.Count())
+	all := c.All()
+	require.Len(t, all, 2)
+	assert.Equal(t, "a", all[0].Args)
+	assert.Equal(t, "b", all[1].Args)
+	assert.Equal(t, "Get", all[0].Name)
+}
+
+func TestCallString(t *testing.T) {
+	assert.Equal(t, `Get("a")`, Call[string, int]{Name: "Get", Args: "a"}.String())
+	assert.Equal(t, `Put({"count":1,"key":"a"})`,
+		Call[testArgs, int]{Name: "Put", Args: testArgs{Key: "a", Count: 1}}.String())
+}
+
+func TestProgrammedResults(t *testing.T) {
+	c := New[string, int]("Get").Returns(1)
+	assert.Equal(t, 1, c.Call("a"))
+
+	c.ReturnsFunc(func(s string) int { return len(s) })
+	assert.Equal(t, 3, c.Call("abc"))
+
+	c.ReturnsWhen(matchers.Equal("x"), 10)
+	c.ReturnsWhen(matchers.StringHasPrefix("x"), 20)
+	assert.Equal(t, 10, c.Call("x"))
+	assert.Equal(t, 20, c.Call("xy"))
+	assert.Equal(t, 1, c.Call("y"))
+
+	c.ReturnsOnce(100, 200)
+	assert.Equal(t, 100, c.Call("x"))
+	assert.Equal(t, 200, c.Call("y"))
+	assert.Equal(t, 10, c.Call("x"))
+
+	assert.Equal(t, []int{1, 3, 10, 20, 1, 100, 200, 10}, resultsOf(c.All()))
+}
+
+func TestReturnsFuncCanCallTheSameMock(t *testing.T) {
+	var c *Calls[int, int]
+	c = New[int, int]("Fib").ReturnsFunc(func(n int) int {
+		if n < 2 {
+			return n
+		}
+		return c.Call(n-1) + c.Call(n-2)
+	})
+	done := make(chan int, 1)
+	go func() { done <- c.Call(4) }()
+	assert.Equal(t, 3, helpers.RequireValue(t, done, time.Second))
+	assert.Equal(t, 9, c.Count())
+	assert.Equal(t, 4, c.All()[8].Args) // the outer call is recorded last
+}
+
+func resultsOf[Args, Result any](calls []Call[Args, Result]) []Result {
+	var ret []Result
+	for _, c := range calls {
+		ret = append(ret, c.Result)
+	}
+	return ret
+}
+
+func TestChan(t *testing.T) {
+	c := New[string, int]("Get")
+	c.Call("a")
+	ch := c.Chan()
+	go c.Call("b")
+	assert.Equal(t, "a", helpers.RequireValue(t, ch, time.Second).Args)
+	assert.Equal(t, "b", helpers.RequireValue(t, ch, time.Second).Args)
+	_, ok, closed := helpers.TryReceive(ch, time.Millisecond)
+	assert.False(t, ok)
+	assert.False(t, closed)
+}
+
+func TestClose(t *testing.T) {
+	helpers.CheckGoroutineLeaks(t)
+	c := New[string, int]("Get")
+	ch := c.Chan()
+	for i := 0; i < 150; i++ { // more than the channel's buffer, so the rest are queued
+		c.Call("a")
+	}
+	c.Close()
+
+	drained := make(chan struct{})
+	go func() {
+		for range ch { //nolint:revive // only waiting for the channel to be closed
+		}
+		close(drained)
+	}()
+	helpers.AssertChannelClosed(t, drained, time.Second)
+
+	c.Call("b")
+	assert.Equal(t, 151, c.Count())
+}
+
+func TestRequireCall(t *testing.T) {
+	c := New[string, int]("Get")
+	go c.Call("a")
+	assert.Equal(t, "a", c.RequireCall(t, time.Second).Args)
+
+	testbox.ShouldFailAndExitEarly(t, func(t testbox.TestingT) {
+		c.RequireCall(t, time.Millisecond)
+	})
+}
+
+func TestRequireCallMatching(t *testing.T) {
+	helpers.SetTimeoutScale(1) // the failure messages below would include a scaled timeout otherwise
+	t.Cleanup(func() { helpers.SetTimeoutScale(0) })
+
+	c := New[string, int]("Get")
+	go func() {
+		c.Call("a")
+		c.Call("b")
+	}()
+	assert.Equal(t, "b", c.RequireCallMatching(t, matchers.Equal("b"), time.Second).Args)
+
+	c.Call("c")
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		c.RequireCallMatching(t, matchers.Equal("d"), time.Millisecond)
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected a matching mockcall.Call[string,int] value from channel but did not receive one in 1ms; "+
+		"discarded 1 non-matching value(s):\nGet(\"c\") (args did not equal \"d\")", result.Failures[0].Message)
+}
+
+func TestAssertCount(t *testing.T) {
+	c := New[string, int]("Get")
+	assert.True(t, c.AssertCount(t, 0))
+	c.Call("a")
+	c.Call("b")
+	assert.True(t, c.AssertCount(t, 2))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		c.AssertCount(t, 1, "custom %s", "message")
+	})
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "expected 1 call(s) to Get, but there were 2:\nGet(\"a\")\nGet(\"b\")", result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		New[string, int]("Put").AssertCount(t, 1)
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected 1 call(s) to Put, but there were 0", result.Failures[0].Message)
+}
+
+func TestAssertCalled(t *testing.T) {
+	c := New[testArgs, int]("Put")
+	c.Call(testArgs{Key: "a", Count: 1})
+	assert.True(t, c.AssertCalled(t, matchers.Equal(testArgs{Key: "a", Count: 1})))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		c.AssertCalled(t, matchers.Equal(testArgs{Key: "b"}))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `expected a call to Put with args (equal to {"count":0,"key":"b"}), but there was none:`+"\n"+
+		`Put({"count":1,"key":"a"})`, result.Failures[0].Message)
+}
+
+func TestAssertNotCalled(t *testing.T) {
+	c := New[string, int]("Get")
+	c.Call("a")
+	c.Call("b")
+	c.Call("a")
+	assert.True(t, c.AssertNotCalled(t, matchers.Equal("c")))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		c.AssertNotCalled(t, matchers.Equal("a"), helpers.FailureOptionDumpGoroutines())
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected no call to Get with args (equal to \"a\"), but there were 2:\nGet(\"a\")\nGet(\"a\")",
+		result.Failures[0].Message)
+}
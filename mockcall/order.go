@@ -0,0 +1,116 @@
+package mockcall
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Expectation describes a call that is expected to happen, for use with AssertOrder. Use
+// Calls.Expect to create one.
+type Expectation interface {
+	describe() string
+	calls() []orderedCall
+}
+
+type orderedCall struct {
+	sequenceNumber uint64
+	description    string
+	matched        bool
+}
+
+type callExpectation[Args, Result any] struct {
+	owner       *Calls[Args, Result]
+	argsMatcher matchers.Matcher
+}
+
+// Expect returns an Expectation of a call to this method whose args match the matcher, for use
+// with AssertOrder.
+func (c *Calls[Args, Result]) Expect(argsMatcher matchers.Matcher) Expectation {
+	return callExpectation[Args, Result]{owner: c, argsMatcher: argsMatcher}
+}
+
+func (e callExpectation[Args, Result]) describe() string {
+	return fmt.Sprintf("%s with args (%s)", e.owner.name, e.argsMatcher.Describe())
+}
+
+func (e callExpectation[Args, Result]) calls() []orderedCall {
+	all := e.owner.All()
+	ret := make([]orderedCall, 0, len(all))
+	for _, call := range all {
+		pass, _ := e.argsMatcher.Test(call.Args)
+		ret = append(ret, orderedCall{sequenceNumber: call.sequenceNumber, description: call.String(), matched: pass})
+	}
+	return ret
+}
+
+// AssertOrder asserts that calls matching the expectations have happened in the specified order,
+// which can include calls to different mocks. Other calls can happen before, after, or in between
+// them. On failure, it lists all of the calls to the mocks that are involved, in order.
+//
+//	mockcall.AssertOrder(t,
+//	    store.get.Expect(matchers.Equal("a")),
+//	    cache.put.Expect(matchers.Equal("a")),
+//	)
+func AssertOrder(t assert.TestingT, expectations ...Expectation) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	var after uint64
+	for i, e := range expectations {
+		found := false
+		for _, call := range e.calls() {
+			if call.matched && call.sequenceNumber > after {
+				after, found = call.sequenceNumber, true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		var problem string
+		if i == 0 {
+			problem = "there was no call matching step 1"
+		} else {
+			problem = fmt.Sprintf("there was no call matching step %d after the call matching step %d", i+1, i)
+		}
+		t.Errorf("expected calls in this order:\n%s\nbut %s; %s",
+			describeExpectations(expectations), problem, describeAllCalls(expectations))
+		return false
+	}
+	return true
+}
+
+func describeExpectations(expectations []Expectation) string {
+	lines := make([]string, 0, len(expectations))
+	for i, e := range expectations {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, e.describe()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func describeAllCalls(expectations []Expectation) string {
+	bySequenceNumber := make(map[uint64]string)
+	for _, e := range expectations {
+		for _, call := range e.calls() {
+			bySequenceNumber[call.sequenceNumber] = call.description
+		}
+	}
+	if len(bySequenceNumber) == 0 {
+		return "no calls were made"
+	}
+	sequenceNumbers := make([]uint64, 0, len(bySequenceNumber))
+	for n := range bySequenceNumber {
+		sequenceNumbers = append(sequenceNumbers, n)
+	}
+	sort.Slice(sequenceNumbers, func(i, j int) bool { return sequenceNumbers[i] < sequenceNumbers[j] })
+	lines := make([]string, 0, len(sequenceNumbers))
+	for _, n := range sequenceNumbers {
+		lines = append(lines, bySequenceNumber[n])
+	}
+	return "actual calls were:\n" + strings.Join(lines, "\n")
+}
@@ -0,0 +1,52 @@
+package mockcall
+
+import (
+	"testing"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertOrderSuccess(t *testing.T) {
+	get, put := New[string, int]("Get"), New[string, bool]("Put")
+	get.Call("a")
+	put.Call("x")
+	get.Call("b")
+	put.Call("y")
+
+	assert.True(t, AssertOrder(t, get.Expect(matchers.Equal("a")), put.Expect(matchers.Equal("y"))))
+	assert.True(t, AssertOrder(t, get.Expect(matchers.Equal("a")), get.Expect(matchers.Equal("b"))))
+	assert.True(t, AssertOrder(t,
+		put.Expect(matchers.Equal("x")), get.Expect(matchers.Equal("b")), put.Expect(matchers.Equal("y"))))
+	assert.True(t, AssertOrder(t))
+}
+
+func TestAssertOrderFailure(t *testing.T) {
+	get, put := New[string, int]("Get"), New[string, bool]("Put")
+	get.Call("a")
+	put.Call("x")
+	get.Call("b")
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertOrder(t, get.Expect(matchers.Equal("b")), put.Expect(matchers.Equal("x")))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected calls in this order:\n"+
+		"1. Get with args (equal to \"b\")\n"+
+		"2. Put with args (equal to \"x\")\n"+
+		"but there was no call matching step 2 after the call matching step 1; actual calls were:\n"+
+		"Get(\"a\")\nPut(\"x\")\nGet(\"b\")",
+		result.Failures[0].Message)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertOrder(t, New[string, int]("Other").Expect(matchers.Equal("b")))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected calls in this order:\n"+
+		"1. Other with args (equal to \"b\")\n"+
+		"but there was no call matching step 1; no calls were made",
+		result.Failures[0].Message)
+}
@@ -0,0 +1,28 @@
+// Package mockcall provides a generic building block for hand-written fakes of interfaces: a Calls
+// value records every call to one method, returns programmed results, and provides assertions and
+// waits about the calls.
+//
+//	type fakeStore struct {
+//	    get *mockcall.Calls[string, getResult]
+//	}
+//
+//	type getResult struct {
+//	    item Item
+//	    err  error
+//	}
+//
+//	func (f *fakeStore) Get(key string) (Item, error) {
+//	    r := f.get.Call(key)
+//	    return r.item, r.err
+//	}
+//
+//	func TestSomething(t *testing.T) {
+//	    store := &fakeStore{get: mockcall.New[string, getResult]("Get")}
+//	    store.get.ReturnsWhen(matchers.Equal("missing"), getResult{err: ErrNotFound})
+//	    thing := NewThingUnderTest(store)
+//	    go thing.Refresh("missing")
+//	    store.get.RequireCallMatching(t, matchers.Equal("missing"), time.Second)
+//	}
+//
+// For methods with several parameters or results, use a struct type for Args or Result.
+package mockcall
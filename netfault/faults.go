@@ -0,0 +1,170 @@
+package netfault
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+)
+
+type faultConfig struct {
+	latency     time.Duration
+	jitter      time.Duration
+	jitterRand  *rand.Rand
+	bandwidth   int
+	hang        bool
+	corruption  float64
+	corruptRand *rand.Rand
+}
+
+type chunkPlan struct {
+	delay        time.Duration
+	transmitTime time.Duration
+	hang         bool
+	corrupted    int
+}
+
+// SetLatency causes each chunk of data that the proxy forwards, in either direction, to be delayed
+// by the specified duration. Zero removes the delay.
+//
+// Since chunks are forwarded one at a time, this also limits throughput; it is meant for making a
+// connection noticeably slow, not for accurately modeling a long-distance link.
+func (p *Proxy) SetLatency(latency time.Duration) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults.latency = latency
+}
+
+// SetJitter causes each chunk of data that the proxy forwards to be delayed by an additional random
+// duration between zero and maxJitter, on top of any latency from SetLatency. The delays are taken
+// from the specified random source, so a test can reproduce them by using the same seed. Zero
+// removes the jitter.
+func (p *Proxy) SetJitter(maxJitter time.Duration, source *rand.Rand) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults.jitter, p.faults.jitterRand = maxJitter, source
+}
+
+// SetBandwidth limits the rate at which the proxy forwards data on each connection, in each
+// direction, to approximately the specified number of bytes per second. Zero removes the limit.
+func (p *Proxy) SetBandwidth(bytesPerSecond int) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults.bandwidth = bytesPerSecond
+}
+
+// SetHanging, if hanging is true, causes the proxy to silently discard all data in both directions
+// while keeping the connections open, as if the network had gone away without either side being
+// told: a "half-open" connection. New connections are still accepted, but no data gets through
+// them. The end of a stream is still passed along if one side closes its connection. Setting it to
+// false lets data flow again; data that was discarded in the meantime is not recovered.
+func (p *Proxy) SetHanging(hanging bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults.hang = hanging
+}
+
+// SetCorruption causes each byte that the proxy forwards, in either direction, to have one bit
+// changed with the specified probability, between 0 and 1. The choices are taken from the specified
+// random source, so a test can reproduce them by using the same seed. Zero removes the corruption.
+func (p *Proxy) SetCorruption(probability float64, source *rand.Rand) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults.corruption, p.faults.corruptRand = probability, source
+}
+
+// ClearFaults removes all of the faults that were set with SetLatency, SetJitter, SetBandwidth,
+// SetHanging, and SetCorruption. It does not affect SetRefusing.
+func (p *Proxy) ClearFaults() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.faults = faultConfig{}
+}
+
+// SetRefusing, if refusing is true, stops the proxy from listening, so that attempts to connect to
+// it fail with "connection refused". Connections that are already open are not affected; use
+// ResetConnections to close them. Setting it to false makes the proxy listen on the same address
+// again.
+//
+// An error is returned if the proxy has been closed, or if it cannot listen on the same address
+// again, which could happen if some other process has taken the port in the meantime.
+func (p *Proxy) SetRefusing(refusing bool) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return errors.New("proxy has been closed")
+	}
+	if refusing {
+		if p.listener != nil {
+			_ = p.listener.Close()
+			p.listener = nil
+		}
+		return nil
+	}
+	if p.listener == nil {
+		listener, err := net.Listen("tcp", p.addr)
+		if err != nil {
+			return fmt.Errorf("can't listen on %s again: %w", p.addr, err)
+		}
+		p.listener = listener
+		p.workers.Add(1)
+		go p.acceptConnections(listener)
+	}
+	return nil
+}
+
+// ResetConnections abruptly closes all of the proxy's open connections, so that both the client
+// and the upstream server get a "connection reset" error. New connections are still accepted.
+func (p *Proxy) ResetConnections() {
+	p.lock.Lock()
+	var open []*proxyConn
+	for _, pc := range p.conns {
+		if pc.stats.IsOpen() {
+			pc.stats.Reset = true
+			open = append(open, pc)
+		}
+	}
+	p.lock.Unlock()
+	for _, pc := range open {
+		pc.close(true)
+	}
+}
+
+// chunkSize returns the maximum number of bytes to forward at a time. With a bandwidth limit, we
+// use small chunks so that the data arrives at a steady rate rather than in bursts.
+func (p *Proxy) chunkSize() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.faults.bandwidth > 0 {
+		return min(proxyBufferSize, max(1, p.faults.bandwidth/10))
+	}
+	return proxyBufferSize
+}
+
+// applyFaults decides what to do with a chunk of data according to the current faults, corrupting
+// it in place if appropriate.
+func (p *Proxy) applyFaults(data []byte) chunkPlan {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	f := p.faults
+	if f.hang {
+		return chunkPlan{hang: true}
+	}
+	plan := chunkPlan{delay: f.latency}
+	if f.jitter > 0 && f.jitterRand != nil {
+		plan.delay += time.Duration(f.jitterRand.Int63n(int64(f.jitter) + 1))
+	}
+	if f.bandwidth > 0 {
+		plan.transmitTime = time.Duration(len(data)) * time.Second / time.Duration(f.bandwidth)
+	}
+	if f.corruption > 0 && f.corruptRand != nil {
+		for i := range data {
+			if f.corruptRand.Float64() < f.corruption {
+				data[i] ^= 1 << f.corruptRand.Intn(8)
+				plan.corrupted++
+			}
+		}
+	}
+	return plan
+}
@@ -0,0 +1,164 @@
+package netfault
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func roundTrip(t *testing.T, conn net.Conn, tr *helpers.TimedReader, data string) time.Duration {
+	start := time.Now()
+	_, err := conn.Write([]byte(data))
+	require.NoError(t, err)
+	received, err := tr.ReadN(len(data), 5*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, data, string(received))
+	return time.Since(start)
+}
+
+func TestSetLatency(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+	latency := 50 * time.Millisecond
+
+	p.SetLatency(latency)
+	assert.GreaterOrEqual(t, int64(roundTrip(t, conn, tr, "a")), int64(latency*2)) // delayed in both directions
+
+	p.SetLatency(0)
+	assert.Less(t, int64(roundTrip(t, conn, tr, "b")), int64(latency))
+}
+
+func TestSetJitter(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+	maxJitter := 30 * time.Millisecond
+
+	p.SetLatency(20 * time.Millisecond)
+	p.SetJitter(maxJitter, rand.New(rand.NewSource(1)))
+	for i := 0; i < 5; i++ {
+		elapsed := roundTrip(t, conn, tr, "x")
+		assert.GreaterOrEqual(t, int64(elapsed), int64(40*time.Millisecond))
+	}
+}
+
+func TestSetBandwidth(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+
+	p.SetBandwidth(2000)
+	elapsed := roundTrip(t, conn, tr, strings.Repeat("x", 400))
+	assert.GreaterOrEqual(t, int64(elapsed), int64(200*time.Millisecond)) // at least 400 bytes at 2000 bytes/s, twice
+
+	p.SetBandwidth(0)
+	assert.Less(t, int64(roundTrip(t, conn, tr, strings.Repeat("x", 400))), int64(100*time.Millisecond))
+}
+
+func TestSetHanging(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+	roundTrip(t, conn, tr, "a")
+
+	p.SetHanging(true)
+	_, err := conn.Write([]byte("bcd"))
+	require.NoError(t, err)
+	_, err = tr.ReadN(1, 100*time.Millisecond)
+	assert.True(t, errors.Is(err, helpers.ErrReadTimeout))
+	assert.Equal(t, int64(3), p.Connections()[0].BytesDropped)
+	assert.True(t, p.Connections()[0].IsOpen())
+
+	p.SetHanging(false)
+	roundTrip(t, conn, tr, "e")
+	assert.Equal(t, int64(2), p.Connections()[0].BytesToUpstream)
+}
+
+func TestSetCorruption(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+
+	p.SetCorruption(1, rand.New(rand.NewSource(1)))
+	_, err := conn.Write([]byte("abc"))
+	require.NoError(t, err)
+	data, err := tr.ReadN(3, time.Second)
+	require.NoError(t, err)
+	// Each byte had one bit flipped going to the server, and one bit flipped coming back; those could
+	// be the same bit, so all we know is that the stats recorded every byte as corrupted twice.
+	assert.Len(t, data, 3)
+	assert.Equal(t, int64(6), p.Connections()[0].BytesCorrupted)
+
+	p.ClearFaults()
+	roundTrip(t, conn, tr, "def")
+	assert.Equal(t, int64(6), p.Connections()[0].BytesCorrupted)
+}
+
+func TestSetCorruptionWithPartialProbability(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+
+	p.SetCorruption(0.5, rand.New(rand.NewSource(1)))
+	original := strings.Repeat("x", 1000)
+	_, err := conn.Write([]byte(original))
+	require.NoError(t, err)
+	data, err := tr.ReadN(len(original), time.Second)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, string(data))
+	corrupted := p.Connections()[0].BytesCorrupted
+	assert.Greater(t, corrupted, int64(500))
+	assert.Less(t, corrupted, int64(1500))
+}
+
+func TestSetRefusing(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+
+	require.NoError(t, p.SetRefusing(true))
+	require.NoError(t, p.SetRefusing(true)) // no effect
+	_, err := net.Dial("tcp", p.Addr())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED), "unexpected error: %s", err)
+
+	roundTrip(t, conn, tr, "still works") // existing connection is unaffected
+
+	require.NoError(t, p.SetRefusing(false))
+	conn2, tr2 := dialProxy(t, p)
+	roundTrip(t, conn2, tr2, "works again")
+	assert.Len(t, p.Connections(), 2)
+}
+
+func TestResetConnections(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+	roundTrip(t, conn, tr, "a")
+
+	p.ResetConnections()
+	_, err := tr.ReadAll(time.Second)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, syscall.ECONNRESET), "unexpected error: %s", err)
+
+	stats := p.Connections()[0]
+	assert.True(t, stats.Reset)
+	assert.False(t, stats.IsOpen())
+
+	conn2, tr2 := dialProxy(t, p) // new connections are still accepted
+	roundTrip(t, conn2, tr2, "b")
+	assert.False(t, p.Connections()[1].Reset)
+}
+
+func TestClearFaults(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	conn, tr := dialProxy(t, p)
+
+	p.SetLatency(time.Second)
+	p.SetJitter(time.Second, rand.New(rand.NewSource(1)))
+	p.SetBandwidth(1)
+	p.ClearFaults()
+	assert.Less(t, int64(roundTrip(t, conn, tr, "abc")), int64(500*time.Millisecond))
+}
@@ -0,0 +1,19 @@
+// Package netfault provides a local TCP proxy that can simulate network problems, such as latency,
+// limited bandwidth, dropped connections, or an unreachable host, between the code under test and
+// any TCP server, including an httptest.Server.
+//
+// Faults can be changed at any time while the proxy is running, so a test can let a client connect
+// successfully and then make the network fail underneath it:
+//
+//	httphelpers.WithServer(handler, func(server *httptest.Server) {
+//	    netfault.WithProxyForServer(server, func(proxy *netfault.Proxy) {
+//	        client := NewClient(proxy.URL())
+//	        // ... client connects normally
+//	        proxy.ResetConnections()
+//	        _ = proxy.SetRefusing(true)
+//	        // ... client should now be retrying
+//	        _ = proxy.SetRefusing(false)
+//	        // ... client should reconnect
+//	    })
+//	})
+package netfault
@@ -0,0 +1,348 @@
+package netfault
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+)
+
+const (
+	proxyBufferSize     = 32 * 1024
+	upstreamDialTimeout = 5 * time.Second
+)
+
+// Proxy is a TCP proxy that listens on a local port and forwards each connection to an upstream
+// address, applying whatever faults are currently configured. See the package documentation.
+//
+// A Proxy is safe for concurrent use.
+type Proxy struct {
+	upstreamAddr string
+	scheme       string
+	addr         string
+	listener     net.Listener
+	conns        []*proxyConn
+	faults       faultConfig
+	closed       bool
+	workers      sync.WaitGroup
+	lock         sync.Mutex
+}
+
+// ConnectionStats describes a connection that was accepted by a Proxy.
+type ConnectionStats struct {
+	// ID identifies the connection: 1 for the first connection accepted by the proxy, 2 for the
+	// second, etc.
+	ID int
+	// ClientAddr is the address of the client that connected to the proxy.
+	ClientAddr string
+	// Opened is when the proxy accepted the connection.
+	Opened time.Time
+	// Closed is when the connection was closed, or the zero value if it is still open.
+	Closed time.Time
+	// BytesToUpstream is the number of bytes that were forwarded from the client to the upstream
+	// server.
+	BytesToUpstream int64
+	// BytesToClient is the number of bytes that were forwarded from the upstream server to the
+	// client.
+	BytesToClient int64
+	// BytesDropped is the number of bytes in either direction that were discarded because of
+	// SetHanging.
+	BytesDropped int64
+	// BytesCorrupted is the number of bytes in either direction that were changed because of
+	// SetCorruption.
+	BytesCorrupted int64
+	// Reset is true if the connection was closed by ResetConnections.
+	Reset bool
+	// UpstreamError is the error from connecting to the upstream server, if that failed. In that
+	// case the proxy resets the client connection right away.
+	UpstreamError error
+}
+
+// IsOpen returns true if the connection has not been closed.
+func (s ConnectionStats) IsOpen() bool {
+	return s.Closed.IsZero()
+}
+
+type proxyConn struct {
+	proxy    *Proxy
+	stats    ConnectionStats
+	client   net.Conn
+	upstream net.Conn
+	done     chan struct{}
+}
+
+// NewProxy starts a Proxy that forwards connections to the specified upstream address, such as
+// "localhost:8000", and ensures that the proxy is closed when the test completes.
+//
+//	proxy := netfault.NewProxy(t, redisAddr)
+//	client := NewRedisClient(proxy.Addr())
+func NewProxy(t helpers.CleanupT, upstreamAddr string) *Proxy {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	p, err := startProxy(upstreamAddr, "http")
+	if err != nil {
+		t.Errorf("can't start proxy: %s", err)
+		t.FailNow()
+		return nil
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+// WithProxy starts a Proxy that forwards connections to the specified upstream address, passes it
+// to the given function, and ensures that the proxy is closed afterward.
+//
+// To close the proxy at the end of the test instead, use NewProxy.
+func WithProxy(upstreamAddr string, action func(*Proxy)) {
+	helpers.WithCleanupScope(func(t helpers.CleanupT) {
+		action(NewProxy(t, upstreamAddr))
+	})
+}
+
+// ProxyForServer starts a Proxy in front of an httptest.Server, and ensures that the proxy is
+// closed when the test completes. Use the proxy's URL method to get a base URL for requests.
+//
+//	server := httphelpers.Server(t, handler)
+//	proxy := netfault.ProxyForServer(t, server)
+//	resp, err := server.Client().Get(proxy.URL() + "/path")
+func ProxyForServer(t helpers.CleanupT, server *httptest.Server) *Proxy {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	scheme := "http"
+	if u, err := url.Parse(server.URL); err == nil {
+		scheme = u.Scheme
+	}
+	p, err := startProxy(server.Listener.Addr().String(), scheme)
+	if err != nil {
+		t.Errorf("can't start proxy: %s", err)
+		t.FailNow()
+		return nil
+	}
+	t.Cleanup(p.Close)
+	return p
+}
+
+// WithProxyForServer starts a Proxy in front of an httptest.Server, passes it to the given
+// function, and ensures that the proxy is closed afterward.
+//
+//	httphelpers.WithServer(handler, func(server *httptest.Server) {
+//	    netfault.WithProxyForServer(server, func(proxy *netfault.Proxy) {
+//	        client := NewClient(proxy.URL())
+//	        // ...
+//	    })
+//	})
+//
+// To close the proxy at the end of the test instead, use ProxyForServer.
+func WithProxyForServer(server *httptest.Server, action func(*Proxy)) {
+	helpers.WithCleanupScope(func(t helpers.CleanupT) {
+		action(ProxyForServer(t, server))
+	})
+}
+
+func startProxy(upstreamAddr, scheme string) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	p := &Proxy{
+		upstreamAddr: upstreamAddr,
+		scheme:       scheme,
+		addr:         listener.Addr().String(),
+		listener:     listener,
+	}
+	p.workers.Add(1)
+	go p.acceptConnections(listener)
+	return p, nil
+}
+
+// Addr returns the host and port that clients should connect to. This does not change for the
+// lifetime of the proxy, even if SetRefusing is used.
+func (p *Proxy) Addr() string {
+	return p.addr
+}
+
+// URL returns a base URL for the proxy, such as "http://127.0.0.1:12345". If the proxy was created
+// with ProxyForServer, the scheme is the same as the server's.
+func (p *Proxy) URL() string {
+	return p.scheme + "://" + p.addr
+}
+
+// UpstreamAddr returns the address that the proxy forwards connections to.
+func (p *Proxy) UpstreamAddr() string {
+	return p.upstreamAddr
+}
+
+// Connections returns the current statistics for every connection that the proxy has accepted,
+// including ones that have been closed, in the order they were accepted.
+func (p *Proxy) Connections() []ConnectionStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	ret := make([]ConnectionStats, 0, len(p.conns))
+	for _, pc := range p.conns {
+		ret = append(ret, pc.stats)
+	}
+	return ret
+}
+
+// Close stops the proxy and closes all of its connections. It waits until all of the proxy's
+// goroutines have exited. Calling Close more than once has no effect.
+func (p *Proxy) Close() {
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return
+	}
+	p.closed = true
+	if p.listener != nil {
+		_ = p.listener.Close()
+		p.listener = nil
+	}
+	conns := append([]*proxyConn(nil), p.conns...)
+	p.lock.Unlock()
+	for _, pc := range conns {
+		pc.close(false)
+	}
+	p.workers.Wait()
+}
+
+func (p *Proxy) acceptConnections(listener net.Listener) {
+	defer p.workers.Done()
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			return // the listener was closed
+		}
+		p.lock.Lock()
+		if p.closed {
+			p.lock.Unlock()
+			_ = client.Close()
+			return
+		}
+		pc := &proxyConn{proxy: p, client: client, done: make(chan struct{})}
+		pc.stats = ConnectionStats{ID: len(p.conns) + 1, ClientAddr: client.RemoteAddr().String(), Opened: time.Now()}
+		p.conns = append(p.conns, pc)
+		p.workers.Add(1)
+		p.lock.Unlock()
+		go pc.run()
+	}
+}
+
+func (pc *proxyConn) run() {
+	p := pc.proxy
+	defer p.workers.Done()
+	upstream, err := net.DialTimeout("tcp", p.upstreamAddr, helpers.ScaledTimeout(upstreamDialTimeout))
+	p.lock.Lock()
+	if err != nil {
+		pc.stats.UpstreamError = err
+		p.lock.Unlock()
+		pc.close(true)
+		return
+	}
+	pc.upstream = upstream
+	alreadyClosed := !pc.stats.IsOpen()
+	p.lock.Unlock()
+	if alreadyClosed { // the proxy was closed, or ResetConnections was called, while we were dialing
+		_ = upstream.Close()
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pc.forward(pc.client, upstream, &pc.stats.BytesToUpstream)
+	}()
+	go func() {
+		defer wg.Done()
+		pc.forward(upstream, pc.client, &pc.stats.BytesToClient)
+	}()
+	wg.Wait()
+	pc.close(false)
+}
+
+// forward copies data in one direction until the source reaches the end of its stream or fails, or
+// the connection is closed. The counter is updated while holding the proxy's lock.
+func (pc *proxyConn) forward(from, to net.Conn, counter *int64) {
+	p := pc.proxy
+	buf := make([]byte, proxyBufferSize)
+	for {
+		n, err := from.Read(buf[:p.chunkSize()])
+		if n > 0 {
+			plan := p.applyFaults(buf[:n])
+			p.lock.Lock()
+			pc.stats.BytesCorrupted += int64(plan.corrupted)
+			if plan.hang {
+				pc.stats.BytesDropped += int64(n)
+			}
+			p.lock.Unlock()
+			if !plan.hang {
+				if !pc.sleep(plan.delay + plan.transmitTime) {
+					return
+				}
+				if _, err := to.Write(buf[:n]); err != nil {
+					pc.close(true)
+					return
+				}
+				p.lock.Lock()
+				*counter += int64(n)
+				p.lock.Unlock()
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				// Pass along the end of the stream, but let data keep flowing in the other direction
+				if tc, ok := to.(interface{ CloseWrite() error }); ok {
+					_ = tc.CloseWrite()
+					return
+				}
+			}
+			pc.close(!errors.Is(err, io.EOF))
+			return
+		}
+	}
+}
+
+// sleep waits for the specified duration, returning false if the connection was closed first.
+func (pc *proxyConn) sleep(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-pc.done:
+		return false
+	}
+}
+
+// close closes both sides of the connection, if they are not already closed. If reset is true,
+// they are closed abruptly so that the peers get a "connection reset" error.
+func (pc *proxyConn) close(reset bool) {
+	p := pc.proxy
+	p.lock.Lock()
+	if !pc.stats.IsOpen() {
+		p.lock.Unlock()
+		return
+	}
+	pc.stats.Closed = time.Now()
+	close(pc.done)
+	upstream := pc.upstream
+	p.lock.Unlock()
+	for _, c := range []net.Conn{pc.client, upstream} {
+		if c == nil {
+			continue
+		}
+		if tc, ok := c.(*net.TCPConn); ok && reset {
+			_ = tc.SetLinger(0)
+		}
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,203 @@
+package netfault
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/httphelpers"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startEchoServer starts a TCP server that writes back everything it receives, and closes its side
+// of each connection when the client does.
+func startEchoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func dialProxy(t *testing.T, p *Proxy) (*net.TCPConn, *helpers.TimedReader) {
+	conn, err := net.Dial("tcp", p.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn.(*net.TCPConn), helpers.NewTimedReader(conn)
+}
+
+func TestProxyForwardsDataInBothDirections(t *testing.T) {
+	upstream := startEchoServer(t)
+	p := NewProxy(t, upstream)
+	assert.Equal(t, upstream, p.UpstreamAddr())
+	assert.Equal(t, "http://"+p.Addr(), p.URL())
+
+	conn, tr := dialProxy(t, p)
+	_, err := conn.Write([]byte("hello"))
+	require.NoError(t, err)
+	data, err := tr.ReadN(5, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	require.NoError(t, conn.CloseWrite())
+	_, err = tr.ReadAll(time.Second)
+	require.NoError(t, err)
+
+	helpers.RequireEventually(t, func() bool { return !p.Connections()[0].IsOpen() },
+		matchers.Equal(true), time.Second, time.Millisecond*10)
+	stats := p.Connections()
+	require.Len(t, stats, 1)
+	assert.Equal(t, 1, stats[0].ID)
+	assert.Equal(t, conn.LocalAddr().String(), stats[0].ClientAddr)
+	assert.Equal(t, int64(5), stats[0].BytesToUpstream)
+	assert.Equal(t, int64(5), stats[0].BytesToClient)
+	assert.False(t, stats[0].Reset)
+	assert.NoError(t, stats[0].UpstreamError)
+	assert.False(t, stats[0].Opened.After(stats[0].Closed))
+}
+
+func TestProxyCountsConnectionsSeparately(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	for i := 0; i < 3; i++ {
+		conn, tr := dialProxy(t, p)
+		_, _ = conn.Write([]byte(strings.Repeat("x", i+1)))
+		_, err := tr.ReadN(i+1, time.Second)
+		require.NoError(t, err)
+	}
+	stats := p.Connections()
+	require.Len(t, stats, 3)
+	for i, s := range stats {
+		assert.Equal(t, i+1, s.ID)
+		assert.Equal(t, int64(i+1), s.BytesToClient)
+		assert.True(t, s.IsOpen())
+	}
+}
+
+func TestProxyResetsClientIfUpstreamIsUnavailable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	upstream := listener.Addr().String()
+	_ = listener.Close()
+
+	p := NewProxy(t, upstream)
+	conn, err := net.Dial("tcp", p.Addr())
+	if err == nil { // the reset may arrive before or after the client sees that it is connected
+		defer conn.Close()
+		_, err = helpers.NewTimedReader(conn).ReadAll(time.Second)
+		assert.Error(t, err)
+	}
+
+	helpers.RequireEventually(t, func() bool { return !p.Connections()[0].IsOpen() },
+		matchers.Equal(true), time.Second, time.Millisecond*10)
+	assert.Error(t, p.Connections()[0].UpstreamError)
+}
+
+func TestNewProxyIsClosedAtCleanup(t *testing.T) {
+	upstream := startEchoServer(t)
+	var addr string
+	result := testbox.SandboxTestWithCleanup(func(st testbox.CleanupT) {
+		p := NewProxy(st, upstream)
+		addr = p.Addr()
+		_, tr := dialProxy(t, p)
+		_ = tr
+	})
+	assert.False(t, result.Failed)
+	_, err := net.Dial("tcp", addr)
+	assert.Error(t, err)
+}
+
+func TestWithProxy(t *testing.T) {
+	upstream := startEchoServer(t)
+	var addr string
+	var conn *net.TCPConn
+	var tr *helpers.TimedReader
+	WithProxy(upstream, func(p *Proxy) {
+		addr = p.Addr()
+		conn, tr = dialProxy(t, p)
+		_, _ = conn.Write([]byte("x"))
+		_, err := tr.ReadN(1, time.Second)
+		require.NoError(t, err)
+	})
+	_, err := net.Dial("tcp", addr)
+	assert.Error(t, err)
+	_, err = tr.ReadAll(time.Second)
+	assert.NoError(t, err) // the proxy closed the connection normally
+}
+
+func TestProxyClose(t *testing.T) {
+	p := NewProxy(t, startEchoServer(t))
+	dialProxy(t, p)
+	helpers.RequireEventually(t, func() bool { return len(p.Connections()) == 1 },
+		matchers.Equal(true), time.Second, time.Millisecond*10)
+	p.Close()
+	p.Close() // no effect
+	assert.False(t, p.Connections()[0].IsOpen())
+	assert.Error(t, p.SetRefusing(false))
+}
+
+func TestProxyForServer(t *testing.T) {
+	handler := httphelpers.HandlerWithResponse(200, nil, []byte("hello"))
+
+	t.Run("HTTP", func(t *testing.T) {
+		server := httphelpers.Server(t, handler)
+		p := ProxyForServer(t, server)
+		assert.Equal(t, server.Listener.Addr().String(), p.UpstreamAddr())
+		assert.Equal(t, "http://"+p.Addr(), p.URL())
+
+		resp, err := server.Client().Get(p.URL())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "hello", string(body))
+	})
+
+	t.Run("HTTPS", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+		p := ProxyForServer(t, server)
+		assert.Equal(t, "https://"+p.Addr(), p.URL())
+
+		resp, err := server.Client().Get(p.URL())
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "hello", string(body))
+	})
+}
+
+func TestWithProxyForServer(t *testing.T) {
+	handler := httphelpers.HandlerWithStatus(204)
+	var url string
+	httphelpers.WithServer(handler, func(server *httptest.Server) {
+		WithProxyForServer(server, func(p *Proxy) {
+			url = p.URL()
+			resp, err := http.Get(url)
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, 204, resp.StatusCode)
+			assert.Len(t, p.Connections(), 1)
+		})
+	})
+	_, err := http.Get(url)
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrCaptureTimeout is returned by CaptureOutput if the action did not finish within the timeout.
+var ErrCaptureTimeout = errors.New("action did not finish before timeout")
+
+// CapturedOutput is the result of CaptureOutput.
+type CapturedOutput struct {
+	// Stdout is everything that was written to os.Stdout.
+	Stdout string `json:"stdout"`
+	// Stderr is everything that was written to os.Stderr.
+	Stderr string `json:"stderr"`
+}
+
+// captureOutputLock is held from the time that CaptureOutput redirects os.Stdout and os.Stderr until
+// the time that it restores them, which can be after CaptureOutput returns if the action timed out.
+//
+//nolint:gochecknoglobals // os.Stdout and os.Stderr are process-wide
+var captureOutputLock sync.Mutex
+
+// CaptureOutput calls the action while os.Stdout and os.Stderr are redirected through pipes, and
+// returns everything that was written to them. This is useful for testing code such as command-line
+// entry points that write directly to the console.
+//
+// If the action does not finish within the timeout, CaptureOutput returns whatever output it has
+// received so far, along with ErrCaptureTimeout. Since the action is still running and may still
+// be using os.Stdout and os.Stderr, those are not restored until the action returns; anything that
+// is written to them in the meantime is discarded, and if the action panics, the panic is ignored.
+// Any other call to CaptureOutput waits until they have been restored.
+//
+// If the action panics before the timeout, the panic is re-raised after os.Stdout and os.Stderr
+// are restored.
+//
+// Since os.Stdout and os.Stderr are global, this should not be used in tests that run in parallel
+// with other tests that write output. Also, loggers that were created before CaptureOutput was
+// called, including the standard log package's default logger, still write to the original
+// os.Stderr; use CaptureStdLog to capture their output.
+//
+//	output, err := helpers.CaptureOutput(func() { RunCommand("--help") }, time.Second)
+//	require.NoError(t, err)
+//	assert.Contains(t, output.Stdout, "usage:")
+func CaptureOutput(action func(), timeout time.Duration) (CapturedOutput, error) {
+	captureOutputLock.Lock()
+	stdout, err := newOutputPipe()
+	if err != nil {
+		captureOutputLock.Unlock()
+		return CapturedOutput{}, err
+	}
+	stderr, err := newOutputPipe()
+	if err != nil {
+		stdout.close()
+		captureOutputLock.Unlock()
+		return CapturedOutput{}, err
+	}
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = stdout.writer, stderr.writer
+	restore := func() CapturedOutput {
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		output := CapturedOutput{Stdout: stdout.close(), Stderr: stderr.close()}
+		captureOutputLock.Unlock()
+		return output
+	}
+
+	done := make(chan any, 1)
+	go func() {
+		var panicValue any
+		defer func() {
+			if r := recover(); r != nil {
+				panicValue = r
+			}
+			done <- panicValue
+		}()
+		action()
+	}()
+
+	timer := time.NewTimer(ScaledTimeout(timeout))
+	defer timer.Stop()
+	select {
+	case panicValue := <-done:
+		output := restore()
+		if panicValue != nil {
+			panic(panicValue)
+		}
+		return output, nil
+	case <-timer.C:
+		output := CapturedOutput{Stdout: stdout.discardRest(), Stderr: stderr.discardRest()}
+		go func() {
+			<-done
+			_ = restore()
+		}()
+		return output, ErrCaptureTimeout
+	}
+}
+
+type outputPipe struct {
+	reader  *os.File
+	writer  *os.File
+	buf     bytes.Buffer
+	discard bool
+	copied  sync.WaitGroup
+	lock    sync.Mutex
+}
+
+func newOutputPipe() (*outputPipe, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	p := &outputPipe{reader: r, writer: w}
+	p.copied.Add(1)
+	go func() {
+		defer p.copied.Done()
+		_, _ = io.Copy(p, r)
+	}()
+	return p, nil
+}
+
+// Write is called by the goroutine that is copying data from the pipe.
+func (p *outputPipe) Write(data []byte) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.discard {
+		p.buf.Write(data)
+	}
+	return len(data), nil
+}
+
+// discardRest returns the data that has been read so far, and causes any data that is read after
+// this point to be discarded. The pipe stays open, so writes to it do not fail or block.
+func (p *outputPipe) discardRest() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.discard = true
+	return p.buf.String()
+}
+
+// close closes the write end of the pipe, waits until all of the data has been read, and returns it.
+func (p *outputPipe) close() string {
+	_ = p.writer.Close()
+	p.copied.Wait()
+	_ = p.reader.Close()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.buf.String()
+}
@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	output, err := CaptureOutput(func() {
+		fmt.Println("to stdout")
+		fmt.Fprintln(os.Stderr, "to stderr")
+	}, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, CapturedOutput{Stdout: "to stdout\n", Stderr: "to stderr\n"}, output)
+	assert.Equal(t, oldStdout, os.Stdout)
+	assert.Equal(t, oldStderr, os.Stderr)
+}
+
+func TestCaptureOutputTimeout(t *testing.T) {
+	oldStdout := os.Stdout
+	release := make(chan struct{})
+	output, err := CaptureOutput(func() {
+		fmt.Println("before timeout")
+		<-release
+		fmt.Println("after timeout")
+	}, time.Millisecond*50)
+	assert.True(t, errors.Is(err, ErrCaptureTimeout))
+	assert.Equal(t, CapturedOutput{Stdout: "before timeout\n"}, output)
+
+	// Output from the action after the timeout is discarded, and another capture waits until
+	// the action has returned and os.Stdout has been restored.
+	close(release)
+	output, err = CaptureOutput(func() { fmt.Println("next") }, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, CapturedOutput{Stdout: "next\n"}, output)
+	assert.Equal(t, oldStdout, os.Stdout)
+}
+
+func TestCaptureOutputRepanics(t *testing.T) {
+	oldStdout := os.Stdout
+	assert.PanicsWithValue(t, "oops", func() {
+		_, _ = CaptureOutput(func() { panic("oops") }, time.Second)
+	})
+	assert.Equal(t, oldStdout, os.Stdout)
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"runtime/debug"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type panicOutcome struct {
+	returned   bool
+	panicked   bool
+	panicValue any
+	stack      []byte
+}
+
+// RequirePanics calls the action and asserts that it panics with a value that matches the matcher,
+// returning the value that was passed to panic. If the action returns normally, exits with
+// runtime.Goexit, or panics with a non-matching value, the test fails and stops immediately; in the
+// last case, the failure message includes the stack trace of the panic.
+//
+// Any Matcher can be used. For panics with error values, see matchers.ErrorIs, matchers.ErrorAs,
+// and matchers.ErrorMessage. For panics with string values, see matchers.StringContains. An empty
+// matchers.Matcher{} accepts any value.
+//
+//	helpers.RequirePanics(t, func() { DoSomething(nil) }, matchers.StringContains("nil parameter"))
+//
+// The action is called on a separate goroutine, so that a panic can be distinguished from
+// runtime.Goexit. Therefore, it should not call FailNow or similar methods of the test.
+func RequirePanics(t require.TestingT, action func(), matcher matchers.Matcher, customMessageAndArgs ...any) any {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	outcome := runAndRecover(action)
+	switch {
+	case outcome.panicked:
+		if pass, desc := matcher.Test(outcome.panicValue); !pass {
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"function panicked with an unexpected value: %s\n\npanic stack:\n%s", desc, outcome.stack)
+			t.FailNow()
+		}
+		return outcome.panicValue
+	case outcome.returned:
+		failWithMessageAndArgs(t, customMessageAndArgs, "expected function to panic, but it returned normally")
+	default:
+		failWithMessageAndArgs(t, customMessageAndArgs,
+			"expected function to panic, but it exited with runtime.Goexit (was FailNow called?)")
+	}
+	t.FailNow()
+	return nil // never reached
+}
+
+// AssertNoPanic calls the action and asserts that it returns normally. If it panics, the failure
+// message includes the panic value and the stack trace of the panic. If it exits with
+// runtime.Goexit, that is also reported as a failure.
+//
+// The action is called on a separate goroutine, so that a panic can be distinguished from
+// runtime.Goexit. Therefore, it should not call FailNow or similar methods of the test.
+func AssertNoPanic(t assert.TestingT, action func(), customMessageAndArgs ...any) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	outcome := runAndRecover(action)
+	switch {
+	case outcome.panicked:
+		failWithMessageAndArgs(t, customMessageAndArgs, "function panicked: %s\n\npanic stack:\n%s",
+			matchers.DescribeValue(outcome.panicValue), outcome.stack)
+		return false
+	case outcome.returned:
+		return true
+	default:
+		failWithMessageAndArgs(t, customMessageAndArgs,
+			"function exited with runtime.Goexit instead of returning (was FailNow called?)")
+		return false
+	}
+}
+
+func runAndRecover(action func()) panicOutcome {
+	result := make(chan panicOutcome, 1)
+	go func() {
+		var outcome panicOutcome
+		defer func() {
+			if r := recover(); r != nil {
+				outcome.panicked, outcome.panicValue, outcome.stack = true, r, debug.Stack()
+			}
+			result <- outcome
+		}()
+		action()
+		outcome.returned = true
+	}()
+	return <-result
+}
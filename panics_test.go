@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func panickyFunction(value any) {
+	panic(value)
+}
+
+func TestRequirePanicsSuccess(t *testing.T) {
+	assert.Equal(t, "oops", RequirePanics(t, func() { panic("oops") }, matchers.StringContains("oop")))
+
+	myErr := errors.New("sorry")
+	wrapped := fmt.Errorf("wrapped: %w", myErr)
+	assert.Equal(t, wrapped, RequirePanics(t, func() { panic(wrapped) }, matchers.ErrorIs(myErr)))
+
+	assert.Equal(t, 3, RequirePanics(t, func() { panic(3) }, matchers.Matcher{}))
+}
+
+func TestRequirePanicsWithNonMatchingValue(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequirePanics(t, func() { panickyFunction("oops") }, matchers.StringContains("bad"))
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	msg := result.Failures[0].Message
+	assert.Regexp(t, "^function panicked with an unexpected value: did not contain \"bad\"\n"+
+		"full value was: \"oops\"\n\npanic stack:\ngoroutine ", msg)
+	assert.Contains(t, msg, "panickyFunction(")
+}
+
+func TestRequirePanicsWithNoPanic(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequirePanics(t, func() {}, matchers.Matcher{}, "custom %s", "message")
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "expected function to panic, but it returned normally", result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+}
+
+func TestRequirePanicsWithGoexit(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequirePanics(t, runtime.Goexit, matchers.Matcher{})
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected function to panic, but it exited with runtime.Goexit (was FailNow called?)",
+		result.Failures[0].Message)
+}
+
+func TestAssertNoPanic(t *testing.T) {
+	called := false
+	assert.True(t, AssertNoPanic(t, func() { called = true }))
+	assert.True(t, called)
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertNoPanic(t, func() { panickyFunction(errors.New("sorry")) })
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, "^function panicked: sorry\n\npanic stack:\ngoroutine ", result.Failures[0].Message)
+	assert.Contains(t, result.Failures[0].Message, "panickyFunction(")
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		AssertNoPanic(t, runtime.Goexit)
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "function exited with runtime.Goexit instead of returning (was FailNow called?)",
+		result.Failures[0].Message)
+}
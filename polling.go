@@ -0,0 +1,134 @@
+package helpers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minPollInterval is used instead of any polling interval that is shorter, including zero or a
+// negative interval.
+const minPollInterval = time.Millisecond
+
+// Eventually repeatedly calls getValue, waiting for the specified interval between calls, until
+// the value passes the matcher or the timeout expires. It returns true if the value passed.
+//
+// If the timeout expires first, it logs a failure that includes the last value that was observed
+// and the number of times it was polled. An interval shorter than one millisecond is treated as
+// one millisecond.
+//
+//	helpers.Eventually(t, store.Count, matchers.Equal(3), time.Second, time.Millisecond*10)
+func Eventually[V any](
+	t assert.TestingT,
+	getValue func() V,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	interval time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	_, ok := pollUntilMatch(t, getValue, matcher, timeout, interval, customMessageAndArgs)
+	return ok
+}
+
+// RequireEventually is the same as Eventually, except that it returns the first value that
+// passed the matcher, or forces an immediate test failure and exit if the timeout expires first.
+func RequireEventually[V any](
+	t require.TestingT,
+	getValue func() V,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	interval time.Duration,
+	customMessageAndArgs ...any,
+) V {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	v, ok := pollUntilMatch(t, getValue, matcher, timeout, interval, customMessageAndArgs)
+	if !ok {
+		t.FailNow()
+	}
+	return v
+}
+
+// Consistently repeatedly calls getValue, waiting for the specified interval between calls, until
+// the duration has elapsed, and asserts that the value passes the matcher every time. It stops
+// polling as soon as a value fails. An interval shorter than one millisecond is treated as one
+// millisecond.
+//
+//	helpers.Consistently(t, store.Count, matchers.Equal(0), time.Millisecond*100, time.Millisecond*10)
+func Consistently[V any](
+	t assert.TestingT,
+	getValue func() V,
+	matcher matchers.Matcher,
+	duration time.Duration,
+	interval time.Duration,
+	customMessageAndArgs ...any,
+) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.NewTimer(ScaledTimeout(duration))
+	defer deadline.Stop()
+	ticker := time.NewTicker(max(interval, minPollInterval))
+	defer ticker.Stop()
+	polls := 0
+	for {
+		v := getValue()
+		polls++
+		if pass, desc := matcher.Test(v); !pass {
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"expected value to keep matching for %s, but poll %d returned a value that did not: %s",
+				describeTimeout(duration), polls, desc)
+			return false
+		}
+		select {
+		case <-deadline.C:
+			return true
+		case <-ticker.C:
+		}
+	}
+}
+
+func pollUntilMatch[V any](
+	t assert.TestingT,
+	getValue func() V,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	interval time.Duration,
+	customMessageAndArgs []any,
+) (V, bool) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.NewTimer(ScaledTimeout(timeout))
+	defer deadline.Stop()
+	ticker := time.NewTicker(max(interval, minPollInterval))
+	defer ticker.Stop()
+	polls := 0
+	for {
+		v := getValue()
+		polls++
+		pass, desc := matcher.Test(v)
+		if pass {
+			return v, true
+		}
+		select {
+		case <-deadline.C:
+			// Matcher.Test appends a description of the full value, which we are already showing
+			desc, _, _ = strings.Cut(desc, "\nfull value was: ")
+			failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+				"expected value to match within %s, but it did not after %d polls; last value was %s\n%s",
+				describeTimeout(timeout), polls, matchers.DescribeValue(v), desc)
+			var empty V
+			return empty, false
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventually(t *testing.T) {
+	withTimeoutScale(t, 1)
+	var counter atomic.Int32
+	getValue := func() int { return int(counter.Add(1)) }
+	assert.True(t, Eventually(t, getValue, matchers.Equal(3), time.Second, time.Millisecond))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Eventually(t, func() string { return "no" }, matchers.Equal("yes"),
+			time.Millisecond*20, time.Millisecond*5, "sorry%s", ".")
+	})
+	if assert.Len(t, result.Failures, 2) {
+		assert.Regexp(t, `^expected value to match within 20ms, but it did not after \d+ polls; last value was "no"\n`+
+			`did not equal "yes"$`, result.Failures[0].Message)
+		assert.Equal(t, "sorry.", result.Failures[1].Message)
+	}
+}
+
+func TestRequireEventually(t *testing.T) {
+	var counter atomic.Int32
+	getValue := func() int { return int(counter.Add(1)) }
+	assert.Equal(t, 3, RequireEventually(t, getValue, matchers.Equal(3), time.Second, time.Millisecond))
+
+	testbox.ShouldFailAndExitEarly(t, func(t testbox.TestingT) {
+		RequireEventually(t, func() string { return "no" }, matchers.Equal("yes"),
+			time.Millisecond*20, time.Millisecond*5)
+	})
+}
+
+func TestConsistently(t *testing.T) {
+	withTimeoutScale(t, 1)
+	var polls atomic.Int32
+	assert.True(t, Consistently(t, func() string { polls.Add(1); return "yes" }, matchers.Equal("yes"),
+		time.Millisecond*50, time.Millisecond*5))
+	assert.Greater(t, int(polls.Load()), 1)
+
+	var counter atomic.Int32
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Consistently(t, func() int { return int(counter.Add(1)) }, matchers.Not(matchers.Equal(3)),
+			time.Second, time.Millisecond)
+	})
+	if assert.Len(t, result.Failures, 1) {
+		assert.Equal(t, "expected value to keep matching for 1s, but poll 3 returned a value that did not: "+
+			"expected: not (equal to 3)\nfull value was: 3", result.Failures[0].Message)
+	}
+}
+
+func TestPollingWithNonPositiveInterval(t *testing.T) {
+	var counter atomic.Int32
+	getValue := func() int { return int(counter.Add(1)) }
+	assert.True(t, Eventually(t, getValue, matchers.Equal(3), time.Second, 0))
+	assert.True(t, Consistently(t, func() int { return 1 }, matchers.Equal(1), time.Millisecond*10, -time.Second))
+}
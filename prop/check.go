@@ -0,0 +1,188 @@
+package prop
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultCheckIterations = 100
+	defaultCheckMaxSize    = 100
+	defaultCheckMaxShrinks = 1000
+)
+
+// CheckOption is an optional parameter for Check or CheckMatcher.
+type CheckOption interface {
+	apply(c *checkConfig)
+}
+
+type checkConfig struct {
+	iterations int
+	maxSize    int
+	maxShrinks int
+	seed       int64
+	hasSeed    bool
+}
+
+type iterationsCheckOption int
+
+func (o iterationsCheckOption) apply(c *checkConfig) {
+	c.iterations = int(o)
+}
+
+type maxSizeCheckOption int
+
+func (o maxSizeCheckOption) apply(c *checkConfig) {
+	c.maxSize = int(o)
+}
+
+type maxShrinksCheckOption int
+
+func (o maxShrinksCheckOption) apply(c *checkConfig) {
+	c.maxShrinks = int(o)
+}
+
+type seedCheckOption int64
+
+func (o seedCheckOption) apply(c *checkConfig) {
+	c.seed, c.hasSeed = int64(o), true
+}
+
+// CheckOptionIterations sets the number of random values that are tested. The default is 100.
+func CheckOptionIterations(iterations int) CheckOption {
+	return iterationsCheckOption(iterations)
+}
+
+// CheckOptionMaxSize sets the largest size that is passed to the generator; the size increases
+// gradually from 1 to this value. The default is 100.
+func CheckOptionMaxSize(maxSize int) CheckOption {
+	return maxSizeCheckOption(maxSize)
+}
+
+// CheckOptionMaxShrinks sets the largest number of simpler values that are tested while shrinking a
+// counterexample. The default is 1000.
+func CheckOptionMaxShrinks(maxShrinks int) CheckOption {
+	return maxShrinksCheckOption(maxShrinks)
+}
+
+// CheckOptionSeed sets the seed for the random source. By default, the seed is chosen by
+// helpers.RandSeed.
+func CheckOptionSeed(seed int64) CheckOption {
+	return seedCheckOption(seed)
+}
+
+// Check asserts that the property returns true for every value produced by the generator. If the
+// property returns false or panics for any value, that value is shrunk to the simplest value for
+// which the property still fails, and the test fails with a message that includes the shrunk
+// value, the original value, and the seed that can be used to reproduce the failure. It returns
+// true if the property held for all values.
+//
+//	prop.Check(t, prop.String(), func(s string) bool {
+//	    decoded, err := Decode(Encode(s))
+//	    return err == nil && decoded == s
+//	})
+func Check[T any](t assert.TestingT, gen Gen[T], property func(T) bool, options ...CheckOption) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return check(t, gen, func(value T) (bool, string) {
+		if property(value) {
+			return true, ""
+		}
+		return false, "property returned false"
+	}, options)
+}
+
+// CheckMatcher is the same as Check, except that the property is that every value must pass the
+// matcher. The failure message includes the matcher's description of the failure.
+//
+//	prop.CheckMatcher(t, prop.IntRange(1, 1000), matchers.Transform("formatted count",
+//	    func(v any) (any, error) { return FormatCount(v.(int)), nil }).Should(matchers.StringContains("item")))
+func CheckMatcher[T any](t assert.TestingT, gen Gen[T], matcher matchers.Matcher, options ...CheckOption) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	return check(t, gen, func(value T) (bool, string) {
+		pass, desc := matcher.Test(value)
+		// Matcher.Test appends a description of the full value, which we are already showing
+		reason, _, _ := strings.Cut(desc, "\nfull value was: ")
+		return pass, reason
+	}, options)
+}
+
+func check[T any](t assert.TestingT, gen Gen[T], property func(T) (bool, string), options []CheckOption) bool {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	config := checkConfig{
+		iterations: defaultCheckIterations,
+		maxSize:    defaultCheckMaxSize,
+		maxShrinks: defaultCheckMaxShrinks,
+	}
+	for _, o := range options {
+		o.apply(&config)
+	}
+	if !config.hasSeed {
+		config.seed = helpers.RandSeed()
+	}
+	r := rand.New(rand.NewSource(config.seed)) //nolint:gosec // not used for security
+	test := func(value T) (pass bool, reason string) {
+		defer func() {
+			if p := recover(); p != nil {
+				pass, reason = false, fmt.Sprintf("property panicked: %v", p)
+			}
+		}()
+		return property(value)
+	}
+	for i := 0; i < config.iterations; i++ {
+		size := max(1, (i+1)*config.maxSize/config.iterations)
+		tree := gen.run(r, size)
+		if pass, reason := test(tree.value); !pass {
+			original := tree.value
+			shrunk, reason, shrinks := shrinkFailure(tree, reason, test, config.maxShrinks)
+			msg := fmt.Sprintf("property failed after %d test(s) with seed %d (set %s=%d to reproduce)\n"+
+				"counterexample: %s\n%s",
+				i+1, config.seed, helpers.RandSeedEnvVar, config.seed, matchers.DescribeValue(shrunk), reason)
+			if shrinks > 0 {
+				msg += fmt.Sprintf("\nshrunk %d time(s) from original value: %s", shrinks, matchers.DescribeValue(original))
+			}
+			t.Errorf("%s", msg)
+			return false
+		}
+	}
+	return true
+}
+
+// shrinkFailure repeatedly replaces the failing value with the first simpler value that also
+// fails, until there are none or it has tested maxAttempts values. It returns the simplest failing
+// value, the reason it failed, and the number of times it was shrunk.
+func shrinkFailure[T any](
+	tree shrinkTree[T],
+	reason string,
+	test func(T) (bool, string),
+	maxAttempts int,
+) (T, string, int) {
+	shrinks, attempts := 0, 0
+TreeLoop:
+	for attempts < maxAttempts {
+		for _, child := range tree.children() {
+			if attempts >= maxAttempts {
+				break TreeLoop
+			}
+			attempts++
+			if pass, childReason := test(child.value); !pass {
+				tree, reason = child, childReason
+				shrinks++
+				continue TreeLoop
+			}
+		}
+		break
+	}
+	return tree.value, reason, shrinks
+}
@@ -0,0 +1,107 @@
+package prop
+
+import (
+	"testing"
+
+	helpers "github.com/launchdarkly/go-test-helpers/v3"
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPasses(t *testing.T) {
+	calls := 0
+	assert.True(t, Check(t, Int(), func(n int) bool {
+		calls++
+		return n*2%2 == 0
+	}))
+	assert.Equal(t, defaultCheckIterations, calls)
+}
+
+func TestCheckIterationsOption(t *testing.T) {
+	calls := 0
+	Check(t, Int(), func(int) bool {
+		calls++
+		return true
+	}, CheckOptionIterations(7))
+	assert.Equal(t, 7, calls)
+}
+
+func TestCheckMaxSizeOption(t *testing.T) {
+	Check(t, Int(), func(n int) bool { return n >= -3 && n <= 3 }, CheckOptionMaxSize(3))
+}
+
+func TestCheckShrinksToMinimalCounterexample(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Check(t, SliceOf(Int()), func(s []int) bool {
+			for _, n := range s {
+				if n >= 5 {
+					return false
+				}
+			}
+			return true
+		}, CheckOptionSeed(1))
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `^property failed after \d+ test\(s\) with seed 1 \(set GO_TEST_HELPERS_SEED=1 to reproduce\)\n`+
+		`counterexample: \[5\]\nproperty returned false\nshrunk \d+ time\(s\) from original value: \[.*\]$`,
+		result.Failures[0].Message)
+}
+
+func TestCheckReportsPanic(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Check(t, Int(), func(n int) bool {
+			if n < 0 {
+				panic("negative")
+			}
+			return true
+		}, CheckOptionSeed(1))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `\ncounterexample: -1\nproperty panicked: negative$`, result.Failures[0].Message)
+}
+
+func TestCheckIsReproducibleWithSameSeed(t *testing.T) {
+	var first, second []int
+	Check(t, Int(), func(n int) bool {
+		first = append(first, n)
+		return true
+	}, CheckOptionSeed(99))
+	Check(t, Int(), func(n int) bool {
+		second = append(second, n)
+		return true
+	}, CheckOptionSeed(99))
+	assert.Equal(t, first, second)
+}
+
+func TestCheckUsesSeedFromEnvironment(t *testing.T) {
+	t.Setenv(helpers.RandSeedEnvVar, "12345")
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Check(t, Int(), func(int) bool { return false })
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `^property failed after 1 test\(s\) with seed 12345 `+
+		`\(set GO_TEST_HELPERS_SEED=12345 to reproduce\)\ncounterexample: 0\nproperty returned false`, result.Failures[0].Message)
+}
+
+func TestCheckMaxShrinksOption(t *testing.T) {
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		Check(t, IntRange(1000, 2000), func(int) bool { return false }, CheckOptionMaxShrinks(0), CheckOptionSeed(1))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.NotContains(t, result.Failures[0].Message, "shrunk")
+}
+
+func TestCheckMatcher(t *testing.T) {
+	assert.True(t, CheckMatcher(t, IntRange(0, 10), matchers.AllOf(
+		matchers.Not(matchers.Equal(-1)), matchers.Not(matchers.Equal(11)))))
+
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		CheckMatcher(t, StringOf("abc"), matchers.Not(matchers.StringContains("b")), CheckOptionSeed(1))
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `\ncounterexample: "b"\nexpected: not \(contains "b"\)`, result.Failures[0].Message)
+}
@@ -0,0 +1,244 @@
+package prop
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+)
+
+const maxFilterAttempts = 100
+
+// Gen is a generator of random values of type T, which also knows how to shrink those values to
+// simpler ones. The zero value is not valid; use the functions in this package to create one.
+//
+// The size parameter is a hint for how large or complex the values should be, such as the maximum
+// length of a slice; Check gradually increases it from 1 to 100 by default.
+type Gen[T any] struct {
+	run func(r *rand.Rand, size int) shrinkTree[T]
+}
+
+// Generator is implemented by every Gen, regardless of its type parameter. It is used by functions
+// such as Struct that combine generators of different types.
+type Generator interface {
+	generateAny(r *rand.Rand, size int) shrinkTree[any]
+	valueType() reflect.Type
+}
+
+// shrinkTree is a generated value, along with a lazily computed list of simpler values that it can
+// be shrunk to, simplest first; each of those can be shrunk further in the same way.
+type shrinkTree[T any] struct {
+	value    T
+	children func() []shrinkTree[T]
+}
+
+// New creates a generator from a function that generates values, and an optional function that
+// returns a list of simpler values that a value can be shrunk to, simplest first. To ensure that
+// shrinking ends, repeatedly calling the shrink function on its own results must eventually return
+// an empty list.
+//
+//	evenInts := prop.New(
+//	    func(r *rand.Rand, size int) int { return r.Intn(size+1) * 2 },
+//	    func(n int) []int { if n == 0 { return nil }; return []int{0, n / 4 * 2} },
+//	)
+//
+// It is usually simpler to derive a generator from an existing one with Map or Filter, which
+// automatically shrink in terms of the original generator.
+func New[T any](generate func(r *rand.Rand, size int) T, shrink func(T) []T) Gen[T] {
+	return Gen[T]{run: func(r *rand.Rand, size int) shrinkTree[T] {
+		return unfoldTree(generate(r, size), shrink)
+	}}
+}
+
+// Generate returns a random value.
+func (g Gen[T]) Generate(r *rand.Rand, size int) T {
+	return g.run(r, size).value
+}
+
+func (g Gen[T]) generateAny(r *rand.Rand, size int) shrinkTree[any] {
+	return mapTree(g.run(r, size), func(v T) any { return v })
+}
+
+func (g Gen[T]) valueType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Const returns a generator that always produces the same value.
+func Const[T any](value T) Gen[T] {
+	return Gen[T]{run: func(*rand.Rand, int) shrinkTree[T] {
+		return leafTree(value)
+	}}
+}
+
+// Map returns a generator that transforms the values of another generator. Values are shrunk by
+// shrinking the original value and then transforming it.
+//
+//	evenInts := prop.Map(prop.Int(), func(n int) int { return n * 2 })
+func Map[A, B any](g Gen[A], transform func(A) B) Gen[B] {
+	return Gen[B]{run: func(r *rand.Rand, size int) shrinkTree[B] {
+		return mapTree(g.run(r, size), transform)
+	}}
+}
+
+// Filter returns a generator that only produces values of another generator that pass the
+// predicate, including when shrinking. It panics if it cannot generate a passing value after 100
+// attempts, so the predicate should not reject most values; if it would, it is better to use Map
+// to construct a valid value.
+func Filter[T any](g Gen[T], predicate func(T) bool) Gen[T] {
+	return Gen[T]{run: func(r *rand.Rand, size int) shrinkTree[T] {
+		for i := 0; i < maxFilterAttempts; i++ {
+			if t := g.run(r, size); predicate(t.value) {
+				return filterTree(t, predicate)
+			}
+		}
+		panic(fmt.Sprintf("prop.Filter could not generate a passing value in %d attempts", maxFilterAttempts))
+	}}
+}
+
+// OneOf returns a generator that picks one of the specified generators at random each time. When
+// shrinking, it tries values from the generators that come before the chosen one, so the simplest
+// generator should be first.
+//
+//	ids := prop.OneOf(prop.Const(""), prop.StringOf("abc"))
+//
+// It panics if no generators are specified.
+func OneOf[T any](gens ...Gen[T]) Gen[T] {
+	if len(gens) == 0 {
+		panic("prop.OneOf: at least one generator is required")
+	}
+	return Gen[T]{run: func(r *rand.Rand, size int) shrinkTree[T] {
+		i := r.Intn(len(gens))
+		seed := r.Int63()
+		chosen := gens[i].run(r, size)
+		return shrinkTree[T]{
+			value: chosen.value,
+			children: func() []shrinkTree[T] {
+				var ret []shrinkTree[T]
+				for j := 0; j < i; j++ {
+					jr := rand.New(rand.NewSource(seed + int64(j))) //nolint:gosec // not used for security
+					ret = append(ret, gens[j].run(jr, size))
+				}
+				return append(ret, chosen.children()...)
+			},
+		}
+	}}
+}
+
+// Elements returns a generator that picks one of the specified values at random. When shrinking,
+// it tries the values that come before the chosen one. It panics if no values are specified.
+func Elements[T any](values ...T) Gen[T] {
+	if len(values) == 0 {
+		panic("prop.Elements: at least one value is required")
+	}
+	return Map(IntRange(0, len(values)-1), func(i int) T { return values[i] })
+}
+
+// Resize returns a generator that calls another generator with a different size. This can be used
+// to limit the size of nested values.
+//
+//	smallSlices := prop.Resize(prop.SliceOf(prop.Int()), func(size int) int { return size / 10 })
+func Resize[T any](g Gen[T], resize func(size int) int) Gen[T] {
+	return Gen[T]{run: func(r *rand.Rand, size int) shrinkTree[T] {
+		return g.run(r, max(resize(size), 0))
+	}}
+}
+
+func leafTree[T any](value T) shrinkTree[T] {
+	return shrinkTree[T]{value: value, children: func() []shrinkTree[T] { return nil }}
+}
+
+func unfoldTree[T any](value T, shrink func(T) []T) shrinkTree[T] {
+	if shrink == nil {
+		return leafTree(value)
+	}
+	return shrinkTree[T]{
+		value: value,
+		children: func() []shrinkTree[T] {
+			candidates := shrink(value)
+			ret := make([]shrinkTree[T], 0, len(candidates))
+			for _, c := range candidates {
+				ret = append(ret, unfoldTree(c, shrink))
+			}
+			return ret
+		},
+	}
+}
+
+func mapTree[A, B any](t shrinkTree[A], transform func(A) B) shrinkTree[B] {
+	return shrinkTree[B]{
+		value: transform(t.value),
+		children: func() []shrinkTree[B] {
+			children := t.children()
+			ret := make([]shrinkTree[B], 0, len(children))
+			for _, c := range children {
+				ret = append(ret, mapTree(c, transform))
+			}
+			return ret
+		},
+	}
+}
+
+func filterTree[T any](t shrinkTree[T], predicate func(T) bool) shrinkTree[T] {
+	return shrinkTree[T]{
+		value: t.value,
+		children: func() []shrinkTree[T] {
+			var ret []shrinkTree[T]
+			for _, c := range t.children() {
+				if predicate(c.value) {
+					ret = append(ret, filterTree(c, predicate))
+				}
+			}
+			return ret
+		},
+	}
+}
+
+type pair[A, B any] struct {
+	first  A
+	second B
+}
+
+// zipTrees combines two trees into one whose values are shrunk by shrinking either of the originals.
+func zipTrees[A, B any](a shrinkTree[A], b shrinkTree[B]) shrinkTree[pair[A, B]] {
+	return shrinkTree[pair[A, B]]{
+		value: pair[A, B]{a.value, b.value},
+		children: func() []shrinkTree[pair[A, B]] {
+			var ret []shrinkTree[pair[A, B]]
+			for _, c := range a.children() {
+				ret = append(ret, zipTrees(c, b))
+			}
+			for _, c := range b.children() {
+				ret = append(ret, zipTrees(a, c))
+			}
+			return ret
+		},
+	}
+}
+
+// sliceTree combines trees into a tree of slices, which are shrunk first by removing elements (but
+// not to fewer than minLength) and then by shrinking each element.
+func sliceTree[T any](elements []shrinkTree[T], minLength int) shrinkTree[[]T] {
+	values := make([]T, len(elements))
+	for i, e := range elements {
+		values[i] = e.value
+	}
+	return shrinkTree[[]T]{
+		value: values,
+		children: func() []shrinkTree[[]T] {
+			var ret []shrinkTree[[]T]
+			for chunk := len(elements) - minLength; chunk > 0; chunk /= 2 {
+				for start := 0; start+chunk <= len(elements); start += chunk {
+					removed := append(append([]shrinkTree[T](nil), elements[:start]...), elements[start+chunk:]...)
+					ret = append(ret, sliceTree(removed, minLength))
+				}
+			}
+			for i, e := range elements {
+				for _, c := range e.children() {
+					replaced := append([]shrinkTree[T](nil), elements...)
+					replaced[i] = c
+					ret = append(ret, sliceTree(replaced, minLength))
+				}
+			}
+			return ret
+		},
+	}
+}
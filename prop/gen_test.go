@@ -0,0 +1,141 @@
+package prop
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+func shrinkValues[T any](t shrinkTree[T]) []T {
+	var ret []T
+	for _, c := range t.children() {
+		ret = append(ret, c.value)
+	}
+	return ret
+}
+
+func TestNew(t *testing.T) {
+	g := New(
+		func(r *rand.Rand, size int) int { return size },
+		func(n int) []int {
+			if n == 0 {
+				return nil
+			}
+			return []int{0, n - 1}
+		},
+	)
+	assert.Equal(t, 5, g.Generate(newTestRand(), 5))
+	tree := g.run(newTestRand(), 5)
+	assert.Equal(t, []int{0, 4}, shrinkValues(tree))
+	assert.Equal(t, []int{0, 3}, shrinkValues(tree.children()[1]))
+	assert.Nil(t, shrinkValues(tree.children()[0]))
+}
+
+func TestNewWithoutShrink(t *testing.T) {
+	g := New(func(*rand.Rand, int) string { return "x" }, nil)
+	tree := g.run(newTestRand(), 1)
+	assert.Equal(t, "x", tree.value)
+	assert.Nil(t, shrinkValues(tree))
+}
+
+func TestConst(t *testing.T) {
+	tree := Const("x").run(newTestRand(), 100)
+	assert.Equal(t, "x", tree.value)
+	assert.Nil(t, shrinkValues(tree))
+}
+
+func TestMap(t *testing.T) {
+	tree := Map(IntRange(10, 10), func(n int) string { return string(rune('a' + n)) }).run(newTestRand(), 1)
+	assert.Equal(t, "k", tree.value)
+
+	tree2 := Map(IntRange(0, 4), func(n int) int { return n * 10 }).run(rand.New(rand.NewSource(3)), 1)
+	for _, v := range shrinkValues(tree2) {
+		assert.Equal(t, 0, v%10)
+		assert.Less(t, v, tree2.value)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	g := Filter(IntRange(0, 100), func(n int) bool { return n%2 == 1 })
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := g.run(r, 10)
+		assert.Equal(t, 1, tree.value%2)
+		for _, v := range shrinkValues(tree) {
+			assert.Equal(t, 1, v%2)
+		}
+	}
+
+	impossible := Filter(Int(), func(int) bool { return false })
+	assert.PanicsWithValue(t, "prop.Filter could not generate a passing value in 100 attempts", func() {
+		impossible.Generate(newTestRand(), 10)
+	})
+}
+
+func TestOneOf(t *testing.T) {
+	g := OneOf(Const("a"), Const("b"), Const("c"))
+	r := newTestRand()
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		tree := g.run(r, 10)
+		seen[tree.value] = true
+		switch tree.value {
+		case "a":
+			assert.Nil(t, shrinkValues(tree))
+		case "b":
+			assert.Equal(t, []string{"a"}, shrinkValues(tree))
+		case "c":
+			assert.Equal(t, []string{"a", "b"}, shrinkValues(tree))
+		}
+	}
+	assert.Len(t, seen, 3)
+	assert.PanicsWithValue(t, "prop.OneOf: at least one generator is required", func() { OneOf[string]() })
+}
+
+func TestElements(t *testing.T) {
+	g := Elements("a", "b", "c")
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := g.run(r, 10)
+		assert.Contains(t, []string{"a", "b", "c"}, tree.value)
+		if tree.value == "c" {
+			assert.Equal(t, []string{"a", "b"}, shrinkValues(tree))
+		}
+	}
+	assert.PanicsWithValue(t, "prop.Elements: at least one value is required", func() { Elements[string]() })
+}
+
+func TestResize(t *testing.T) {
+	g := Resize(New(func(_ *rand.Rand, size int) int { return size }, nil), func(size int) int { return size - 10 })
+	assert.Equal(t, 90, g.Generate(newTestRand(), 100))
+	assert.Equal(t, 0, g.Generate(newTestRand(), 5))
+}
+
+func TestSliceTreeShrinking(t *testing.T) {
+	elements := []shrinkTree[int64]{integerTree(1, 0), leafTree[int64](2), leafTree[int64](3), leafTree[int64](4)}
+	tree := sliceTree(elements, 0)
+	assert.Equal(t, []int64{1, 2, 3, 4}, tree.value)
+	assert.Equal(t, [][]int64{
+		{},
+		{3, 4}, {1, 2},
+		{2, 3, 4}, {1, 3, 4}, {1, 2, 4}, {1, 2, 3},
+		{0, 2, 3, 4},
+	}, shrinkValues(tree))
+
+	treeWithMin := sliceTree(elements, 3)
+	assert.Equal(t, [][]int64{
+		{2, 3, 4}, {1, 3, 4}, {1, 2, 4}, {1, 2, 3},
+		{0, 2, 3, 4},
+	}, shrinkValues(treeWithMin))
+}
+
+func TestZipTreesShrinking(t *testing.T) {
+	tree := zipTrees(integerTree(2, 0), integerTree(1, 0))
+	assert.Equal(t, pair[int64, int64]{2, 1}, tree.value)
+	assert.Equal(t, []pair[int64, int64]{{0, 1}, {1, 1}, {2, 0}}, shrinkValues(tree))
+}
@@ -0,0 +1,237 @@
+package prop
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+
+	"github.com/launchdarkly/go-test-helpers/v3/jsonhelpers"
+)
+
+const (
+	printableChars    = " !\"#$%&'()*+,-./0123456789:;<=>?@ABCDEFGHIJKLMNOPQRSTUVWXYZ[\\]^_`abcdefghijklmnopqrstuvwxyz{|}~"
+	jsonKeyChars      = "abcdefghijklmnopqrstuvwxyz"
+	jsonMaxDepth      = 3
+	jsonNestedDivisor = 4
+)
+
+// Int returns a generator of ints between -size and size. Values are shrunk toward zero.
+func Int() Gen[int] {
+	return Map(Int64(), func(n int64) int { return int(n) })
+}
+
+// Int64 returns a generator of int64s between -size and size. Values are shrunk toward zero.
+func Int64() Gen[int64] {
+	return Gen[int64]{run: func(r *rand.Rand, size int) shrinkTree[int64] {
+		n := r.Int63n(2*int64(size)+1) - int64(size)
+		return integerTree(n, 0)
+	}}
+}
+
+// IntRange returns a generator of ints between minValue and maxValue inclusive, regardless of the
+// size. Values are shrunk toward whichever value in the range is closest to zero. It panics if
+// maxValue is less than minValue.
+func IntRange(minValue, maxValue int) Gen[int] {
+	if maxValue < minValue {
+		panic(fmt.Sprintf("prop.IntRange: invalid range %d to %d", minValue, maxValue))
+	}
+	target := min(max(0, minValue), maxValue)
+	return Gen[int]{run: func(r *rand.Rand, _ int) shrinkTree[int] {
+		n := int64(minValue) + r.Int63n(int64(maxValue)-int64(minValue)+1)
+		return mapTree(integerTree(n, int64(target)), func(n int64) int { return int(n) })
+	}}
+}
+
+// Float64 returns a generator of float64s between -size and size. Values are shrunk toward zero,
+// first by removing their fractional parts.
+func Float64() Gen[float64] {
+	return New(
+		func(r *rand.Rand, size int) float64 { return (r.Float64()*2 - 1) * float64(size) },
+		shrinkFloat,
+	)
+}
+
+// Bool returns a generator of bools. True is shrunk to false.
+func Bool() Gen[bool] {
+	return Elements(false, true)
+}
+
+// String returns a generator of strings made of printable ASCII characters, with lengths from 0 to
+// size. Strings are shrunk by removing characters, and by replacing characters with ones that come
+// earlier in the ASCII table, such as spaces.
+func String() Gen[string] {
+	return StringOf(printableChars)
+}
+
+// StringOf returns a generator of strings made of the specified characters, with lengths from 0 to
+// size. Strings are shrunk by removing characters, and by replacing characters with ones that come
+// earlier in chars.
+//
+//	hexStrings := prop.StringOf("0123456789abcdef")
+//
+// It panics if chars is empty.
+func StringOf(chars string) Gen[string] {
+	if chars == "" {
+		panic("prop.StringOf: chars must not be empty")
+	}
+	return Map(SliceOf(Elements([]rune(chars)...)), func(runes []rune) string { return string(runes) })
+}
+
+// SliceOf returns a generator of slices with lengths from 0 to size, whose elements are produced by
+// another generator. Slices are shrunk by removing elements and by shrinking elements.
+func SliceOf[T any](elements Gen[T]) Gen[[]T] {
+	return Gen[[]T]{run: func(r *rand.Rand, size int) shrinkTree[[]T] {
+		return generateSlice(r, size, elements, r.Intn(size+1), 0)
+	}}
+}
+
+// SliceOfN returns a generator of slices with lengths from minLength to maxLength inclusive,
+// regardless of the size, whose elements are produced by another generator. Slices are shrunk by
+// removing elements, but not to fewer than minLength, and by shrinking elements. It panics if
+// minLength is negative or greater than maxLength.
+func SliceOfN[T any](elements Gen[T], minLength, maxLength int) Gen[[]T] {
+	if minLength < 0 || maxLength < minLength {
+		panic(fmt.Sprintf("prop.SliceOfN: invalid length range %d to %d", minLength, maxLength))
+	}
+	return Gen[[]T]{run: func(r *rand.Rand, size int) shrinkTree[[]T] {
+		return generateSlice(r, size, elements, minLength+r.Intn(maxLength-minLength+1), minLength)
+	}}
+}
+
+// MapOf returns a generator of maps with up to size entries, whose keys and values are produced by
+// other generators. Maps are shrunk by removing entries and by shrinking keys and values.
+func MapOf[K comparable, V any](keys Gen[K], values Gen[V]) Gen[map[K]V] {
+	return Gen[map[K]V]{run: func(r *rand.Rand, size int) shrinkTree[map[K]V] {
+		n := r.Intn(size + 1)
+		seen := make(map[K]bool, n)
+		var entries []shrinkTree[pair[K, V]]
+		for i := 0; i < n; i++ {
+			k := keys.run(r, size)
+			if seen[k.value] {
+				continue // we'll just generate fewer entries than n, rather than trying indefinitely
+			}
+			seen[k.value] = true
+			entries = append(entries, zipTrees(k, values.run(r, size)))
+		}
+		return mapTree(sliceTree(entries, 0), func(entries []pair[K, V]) map[K]V {
+			m := make(map[K]V, len(entries))
+			for _, e := range entries {
+				m[e.first] = e.second
+			}
+			return m
+		})
+	}}
+}
+
+// Struct returns a generator of structs of type T, whose fields are produced by the generators in
+// the fields map, keyed by field name. Fields that are not in the map are left as zero values.
+// Structs are shrunk by shrinking each field.
+//
+// It panics if T is not a struct type, or if any of the field names are not exported fields of T,
+// or if a generator's type cannot be assigned to its field.
+//
+//	users := prop.Struct[User](map[string]prop.Generator{
+//	    "Name": prop.String(),
+//	    "Age":  prop.IntRange(0, 120),
+//	})
+func Struct[T any](fields map[string]Generator) Gen[T] {
+	structType := reflect.TypeOf((*T)(nil)).Elem()
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("prop.Struct: %s is not a struct type", structType))
+	}
+	names := make([]string, 0, len(fields))
+	for name, g := range fields {
+		f, ok := structType.FieldByName(name)
+		if !ok || !f.IsExported() {
+			panic(fmt.Sprintf("prop.Struct: %s has no exported field %q", structType, name))
+		}
+		if !g.valueType().AssignableTo(f.Type) {
+			panic(fmt.Sprintf("prop.Struct: cannot assign %s to field %q of type %s", g.valueType(), name, f.Type))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names) // so that the same seed always produces the same values
+	return Gen[T]{run: func(r *rand.Rand, size int) shrinkTree[T] {
+		trees := make([]shrinkTree[any], 0, len(names))
+		for _, name := range names {
+			trees = append(trees, fields[name].generateAny(r, size))
+		}
+		return mapTree(sliceTree(trees, len(trees)), func(values []any) T {
+			var ret T
+			rv := reflect.ValueOf(&ret).Elem()
+			for i, name := range names {
+				if values[i] != nil {
+					rv.FieldByName(name).Set(reflect.ValueOf(values[i]))
+				}
+			}
+			return ret
+		})
+	}}
+}
+
+// JSON returns a generator of JSON values, which can be null, booleans, numbers, strings, arrays,
+// or objects. Arrays and objects can be nested up to three levels deep, and have up to size
+// elements at the top level, and fewer at deeper levels. Values are shrunk toward simpler types of
+// values, such as null, as well as in the same ways as the other generators.
+func JSON() Gen[jsonhelpers.JValue] {
+	return Map(jsonValue(jsonMaxDepth), func(value any) jsonhelpers.JValue {
+		return jsonhelpers.JValueOf(jsonhelpers.ToJSON(value))
+	})
+}
+
+func jsonValue(depth int) Gen[any] {
+	gens := []Gen[any]{
+		Const[any](nil),
+		Map(Bool(), func(b bool) any { return b }),
+		Map(Int(), func(n int) any { return n }),
+		Map(String(), func(s string) any { return s }),
+	}
+	if depth > 0 {
+		nested := Resize(jsonValue(depth-1), func(size int) int { return size / jsonNestedDivisor })
+		gens = append(gens,
+			Map(SliceOf(nested), func(values []any) any { return values }),
+			Map(MapOf(StringOf(jsonKeyChars), nested), func(values map[string]any) any { return values }),
+		)
+	}
+	return OneOf(gens...)
+}
+
+func generateSlice[T any](r *rand.Rand, size int, elements Gen[T], length, minLength int) shrinkTree[[]T] {
+	trees := make([]shrinkTree[T], 0, length)
+	for i := 0; i < length; i++ {
+		trees = append(trees, elements.run(r, size))
+	}
+	return sliceTree(trees, minLength)
+}
+
+// integerTree shrinks toward the target by first trying the target itself, and then values that
+// are successively closer to the original value.
+func integerTree(value, target int64) shrinkTree[int64] {
+	return unfoldTree(value, func(n int64) []int64 {
+		if n == target {
+			return nil
+		}
+		ret := []int64{target}
+		diff := n - target
+		for d := diff / 2; d != 0; d /= 2 {
+			ret = append(ret, n-d)
+		}
+		return ret
+	})
+}
+
+func shrinkFloat(f float64) []float64 {
+	if f == 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	ret := []float64{0}
+	if t := math.Trunc(f); t != f && t != 0 {
+		ret = append(ret, t)
+	}
+	if half := math.Trunc(f / 2); half != 0 && half != f {
+		ret = append(ret, half)
+	}
+	return ret
+}
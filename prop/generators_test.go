@@ -0,0 +1,184 @@
+package prop
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInt(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		n := Int().Generate(r, 5)
+		assert.GreaterOrEqual(t, n, -5)
+		assert.LessOrEqual(t, n, 5)
+	}
+	assert.Equal(t, 0, Int().Generate(r, 0))
+}
+
+func TestIntegerShrinking(t *testing.T) {
+	assert.Equal(t, []int64{0, 5, 8, 9}, shrinkValues(integerTree(10, 0)))
+	assert.Equal(t, []int64{0, -5, -8, -9}, shrinkValues(integerTree(-10, 0)))
+	assert.Equal(t, []int64{3, 4}, shrinkValues(integerTree(5, 3)))
+	assert.Nil(t, shrinkValues(integerTree(3, 3)))
+}
+
+func TestIntRange(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := IntRange(3, 7).run(r, 100)
+		assert.GreaterOrEqual(t, tree.value, 3)
+		assert.LessOrEqual(t, tree.value, 7)
+		if tree.value != 3 {
+			assert.Equal(t, 3, shrinkValues(tree)[0])
+		}
+	}
+	for i := 0; i < 100; i++ {
+		tree := IntRange(-7, -3).run(r, 100)
+		if tree.value != -3 {
+			assert.Equal(t, -3, shrinkValues(tree)[0])
+		}
+	}
+	assert.Panics(t, func() { IntRange(2, 1) })
+}
+
+func TestFloat64(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		f := Float64().Generate(r, 5)
+		assert.GreaterOrEqual(t, f, -5.0)
+		assert.LessOrEqual(t, f, 5.0)
+	}
+	assert.Equal(t, []float64{0, 3, 1}, shrinkFloat(3.5))
+	assert.Equal(t, []float64{0, -2}, shrinkFloat(-4))
+	assert.Equal(t, []float64{0}, shrinkFloat(0.5))
+	assert.Nil(t, shrinkFloat(0))
+}
+
+func TestBool(t *testing.T) {
+	tree := Bool().run(rand.New(rand.NewSource(2)), 1)
+	if tree.value {
+		assert.Equal(t, []bool{false}, shrinkValues(tree))
+	} else {
+		assert.Nil(t, shrinkValues(tree))
+	}
+}
+
+func TestString(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		s := String().Generate(r, 10)
+		assert.LessOrEqual(t, len(s), 10)
+		for _, ch := range s {
+			assert.True(t, ch >= ' ' && ch <= '~', "unexpected character %q", ch)
+		}
+	}
+}
+
+func TestStringOf(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := StringOf("xyz").run(r, 10)
+		assert.Equal(t, "", strings.Trim(tree.value, "xyz"))
+		if tree.value != "" {
+			assert.Equal(t, "", shrinkValues(tree)[0])
+		}
+	}
+	assert.PanicsWithValue(t, "prop.StringOf: chars must not be empty", func() { StringOf("") })
+}
+
+func TestSliceOf(t *testing.T) {
+	r := newTestRand()
+	lengths := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		s := SliceOf(Int()).Generate(r, 3)
+		assert.LessOrEqual(t, len(s), 3)
+		lengths[len(s)] = true
+	}
+	assert.Len(t, lengths, 4)
+}
+
+func TestSliceOfN(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := SliceOfN(Int(), 2, 4).run(r, 100)
+		assert.GreaterOrEqual(t, len(tree.value), 2)
+		assert.LessOrEqual(t, len(tree.value), 4)
+		for _, s := range shrinkValues(tree) {
+			assert.GreaterOrEqual(t, len(s), 2)
+		}
+	}
+	assert.PanicsWithValue(t, "prop.SliceOfN: invalid length range 3 to 2", func() { SliceOfN(Int(), 3, 2) })
+	assert.PanicsWithValue(t, "prop.SliceOfN: invalid length range -1 to 2", func() { SliceOfN(Int(), -1, 2) })
+}
+
+func TestMapOf(t *testing.T) {
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := MapOf(StringOf("ab"), Int()).run(r, 5)
+		assert.LessOrEqual(t, len(tree.value), 5)
+		if len(tree.value) != 0 {
+			assert.Len(t, shrinkValues(tree)[0], 0)
+		}
+	}
+}
+
+type testStruct struct {
+	Name    string
+	Count   int
+	Ignored bool
+	hidden  int
+}
+
+func TestStruct(t *testing.T) {
+	g := Struct[testStruct](map[string]Generator{
+		"Name":  Const("x"),
+		"Count": IntRange(5, 10),
+	})
+	r := newTestRand()
+	for i := 0; i < 100; i++ {
+		tree := g.run(r, 10)
+		assert.Equal(t, "x", tree.value.Name)
+		assert.GreaterOrEqual(t, tree.value.Count, 5)
+		assert.LessOrEqual(t, tree.value.Count, 10)
+		assert.False(t, tree.value.Ignored)
+		if tree.value.Count != 5 {
+			assert.Equal(t, testStruct{Name: "x", Count: 5}, shrinkValues(tree)[0])
+		}
+	}
+}
+
+func TestStructPanicsForInvalidParameters(t *testing.T) {
+	assert.PanicsWithValue(t, "prop.Struct: int is not a struct type", func() {
+		Struct[int](nil)
+	})
+	assert.PanicsWithValue(t, `prop.Struct: prop.testStruct has no exported field "Other"`, func() {
+		Struct[testStruct](map[string]Generator{"Other": Int()})
+	})
+	assert.PanicsWithValue(t, `prop.Struct: prop.testStruct has no exported field "hidden"`, func() {
+		Struct[testStruct](map[string]Generator{"hidden": Int()})
+	})
+	assert.PanicsWithValue(t, `prop.Struct: cannot assign string to field "Count" of type int`, func() {
+		Struct[testStruct](map[string]Generator{"Count": String()})
+	})
+}
+
+func TestJSON(t *testing.T) {
+	r := newTestRand()
+	kinds := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		tree := JSON().run(r, 8)
+		assert.NoError(t, tree.value.Error())
+		kinds[tree.value.String()[0:1]] = true
+		if tree.value.String() != "null" {
+			children := tree.children()
+			assert.Equal(t, "null", children[0].value.String())
+			assert.NoError(t, children[len(children)-1].value.Error())
+		}
+	}
+	for _, k := range []string{"n", "t", "\"", "[", "{"} {
+		assert.True(t, kinds[k], "did not generate any values starting with %s", k)
+	}
+}
@@ -0,0 +1,19 @@
+// Package prop provides property-based testing: a property of some code is checked against many
+// randomly generated inputs, and if it does not hold for one of them, that input is shrunk to a
+// minimal counterexample before it is reported.
+//
+//	func TestReverseTwiceIsIdentity(t *testing.T) {
+//	    prop.Check(t, prop.SliceOf(prop.Int()), func(s []int) bool {
+//	        return slices.Equal(Reverse(Reverse(s)), s)
+//	    })
+//	}
+//
+// Generators (Gen) are built from the primitive generators in this package, such as Int and
+// String, and combined with functions such as SliceOf, MapOf, Struct, Map, and OneOf. Shrinking is
+// integrated with generation, so a generator built by combining others knows how to shrink its
+// values without any extra code.
+//
+// The random values are reproducible: Check uses helpers.RandSeed to choose a seed, and reports
+// the seed if the property fails, so setting the GO_TEST_HELPERS_SEED environment variable to that
+// value will produce the same values again.
+package prop
@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// RandSeedEnvVar is the name of an environment variable that, if set to an integer, is used as
+	// the seed for random sources created by Rand. See RandSeed.
+	RandSeedEnvVar = "GO_TEST_HELPERS_SEED"
+
+	// RandSeedFlagName is the name of an integer command-line flag that, if the test binary defines
+	// it and it is set to a nonzero value, is used as the seed for random sources created by Rand.
+	// See RandSeed.
+	RandSeedFlagName = "seed"
+
+	randomStringChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// RandSeed returns a seed for a random source. If the environment variable GO_TEST_HELPERS_SEED is
+// set to an integer, it returns that value; otherwise, if the test binary defines an integer "-seed"
+// flag and it is set to a nonzero value, it returns that value; otherwise, it returns a value based
+// on the current time.
+//
+// Since this package cannot know which tests will run, it does not define the flag itself:
+//
+//	var _ = flag.Int64("seed", 0, "random seed")
+//
+// Most tests should use Rand instead, which also reports the seed if the test fails.
+func RandSeed() int64 {
+	if seed, err := strconv.ParseInt(os.Getenv(RandSeedEnvVar), 10, 64); err == nil {
+		return seed
+	}
+	if f := flag.Lookup(RandSeedFlagName); f != nil {
+		if seed, err := strconv.ParseInt(f.Value.String(), 10, 64); err == nil && seed != 0 {
+			return seed
+		}
+	}
+	return time.Now().UnixNano()
+}
+
+// Rand returns a random source for use in a test, seeded with RandSeed. If the test fails, the
+// seed is reported at the end of the test, so that the same random values can be produced again
+// by setting GO_TEST_HELPERS_SEED to that value. The seed is logged with t.Logf if t has that
+// method, as *testing.T does; otherwise, it is reported with t.Errorf.
+//
+//	r := helpers.Rand(t)
+//	key := helpers.RandomString(r, 10)
+//	helpers.RunConcurrently(t, 10, action, helpers.ConcurrencyOptionRandomDelay(time.Millisecond, r))
+//
+// The helpers that use random behavior, such as ConcurrencyOptionRandomDelay, ReadShortRandom, and
+// the netfault jitter and corruption settings, take a *rand.Rand parameter, so that a source from
+// Rand can be used to make a failure reproducible.
+//
+// Since a *rand.Rand is not safe for concurrent use, each goroutine that needs random values
+// should have its own; calling Rand more than once in a test is fine, since each source's seed is
+// reported separately.
+func Rand(t CleanupT) *rand.Rand {
+	seed := RandSeed()
+	t.Cleanup(func() {
+		if ft, ok := t.(interface{ Failed() bool }); !ok || !ft.Failed() {
+			return
+		}
+		msg := fmt.Sprintf("random seed for this test was %d; to reproduce, set %s=%d", seed, RandSeedEnvVar, seed)
+		if lt, ok := t.(interface{ Logf(string, ...any) }); ok {
+			lt.Logf("%s", msg)
+		} else {
+			t.Errorf("%s", msg)
+		}
+	})
+	return rand.New(rand.NewSource(seed)) //nolint:gosec // this is deliberately not a secure random source
+}
+
+// RandomString returns a string of the specified length, made of random ASCII letters and digits.
+// If the length is zero or negative, it returns an empty string.
+func RandomString(r *rand.Rand, length int) string {
+	if length <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.Grow(length)
+	for i := 0; i < length; i++ {
+		b.WriteByte(randomStringChars[r.Intn(len(randomStringChars))])
+	}
+	return b.String()
+}
+
+// RandomUUID returns a random string in the format of a version 4 UUID, such as
+// "0b6e3e8c-4a43-4e5f-9b77-2ad3c3a8f9e1".
+func RandomUUID(r *rand.Rand) string {
+	var b [16]byte
+	_, _ = r.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// RandomDuration returns a random duration that is greater than or equal to minDuration, and less
+// than or equal to maxDuration.
+func RandomDuration(r *rand.Rand, minDuration, maxDuration time.Duration) time.Duration {
+	if maxDuration <= minDuration {
+		return minDuration
+	}
+	return minDuration + time.Duration(r.Int63n(int64(maxDuration-minDuration)+1))
+}
@@ -0,0 +1,77 @@
+package helpers
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandSeedFromEnvironment(t *testing.T) {
+	t.Setenv(RandSeedEnvVar, "12345")
+	assert.Equal(t, int64(12345), RandSeed())
+
+	r1, r2 := Rand(t), Rand(t)
+	assert.Equal(t, r1.Int63(), r2.Int63())
+}
+
+func TestRandSeedFromTime(t *testing.T) {
+	t.Setenv(RandSeedEnvVar, "")
+	before := time.Now().UnixNano()
+	seed := RandSeed()
+	assert.GreaterOrEqual(t, seed, before)
+	assert.LessOrEqual(t, seed, time.Now().UnixNano())
+}
+
+func TestRandReportsSeedIfTestFails(t *testing.T) {
+	t.Setenv(RandSeedEnvVar, "12345")
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		_ = Rand(t)
+		t.Errorf("sorry")
+	})
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "random seed for this test was 12345; to reproduce, set GO_TEST_HELPERS_SEED=12345",
+		result.Failures[1].Message)
+}
+
+func TestRandDoesNotReportSeedIfTestPasses(t *testing.T) {
+	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+		_ = Rand(t)
+	})
+	assert.False(t, result.Failed)
+	assert.Len(t, result.Failures, 0)
+}
+
+func TestRandomString(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := RandomString(r, 20)
+	assert.Len(t, s, 20)
+	assert.Regexp(t, `^[a-zA-Z0-9]+$`, s)
+	assert.Equal(t, s, RandomString(rand.New(rand.NewSource(1)), 20))
+	assert.Equal(t, "", RandomString(r, 0))
+	assert.Equal(t, "", RandomString(r, -1))
+}
+
+func TestRandomUUID(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	for i := 0; i < 100; i++ {
+		assert.Regexp(t, uuidPattern, RandomUUID(r))
+	}
+	assert.Equal(t, RandomUUID(rand.New(rand.NewSource(2))), RandomUUID(rand.New(rand.NewSource(2))))
+}
+
+func TestRandomDuration(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		d := RandomDuration(r, time.Millisecond, time.Millisecond*5)
+		assert.GreaterOrEqual(t, int64(d), int64(time.Millisecond))
+		assert.LessOrEqual(t, int64(d), int64(time.Millisecond*5))
+	}
+	assert.Equal(t, time.Second, RandomDuration(r, time.Second, time.Second))
+}
@@ -0,0 +1,277 @@
+package helpers
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/require"
+)
+
+const recorderChanBufferSize = 100
+
+// Recorder is a thread-safe collector of values, for use in callbacks, loggers, mock
+// implementations, or anything else that needs to report values to a test without ever blocking.
+// Unlike a channel, it has no maximum size.
+//
+// The test can inspect the values that have been recorded so far with Snapshot or Len, wait for
+// values with WaitForCount or WaitFor, or consume them as a channel with Chan.
+//
+//	recorder := helpers.NewRecorder[string]()
+//	thing := NewThingUnderTest(func(event string) { recorder.Add(event) })
+//	thing.DoSomething()
+//	recorder.WaitFor(t, matchers.Equal("done"), time.Second)
+type Recorder[V any] struct {
+	values  []V
+	drained int // the total number of values that have been removed by Drain
+	changed chan struct{}
+	ch      chan V
+	queue   []V
+	feeding bool
+	closed  bool
+	done    chan struct{}
+	lock    sync.Mutex
+}
+
+// NewRecorder creates a Recorder.
+func NewRecorder[V any]() *Recorder[V] {
+	return &Recorder[V]{changed: make(chan struct{}), done: make(chan struct{})}
+}
+
+// Add records a value. It never blocks, except very briefly to synchronize with other goroutines.
+//
+// If Chan has been called, the value is also delivered to that channel. If the channel's buffer is
+// full, the value is queued and delivered by a background goroutine as soon as there is room;
+// that goroutine exits whenever the queue is empty.
+func (r *Recorder[V]) Add(value V) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.values = append(r.values, value)
+	close(r.changed)
+	r.changed = make(chan struct{})
+	if r.ch != nil && !r.closed {
+		r.deliver(value)
+	}
+}
+
+// Snapshot returns a copy of all the values that have been recorded so far, in the order they were
+// added, not including any that were removed by Drain.
+func (r *Recorder[V]) Snapshot() []V {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]V(nil), r.values...)
+}
+
+// Len returns the number of values that have been recorded so far, not including any that were
+// removed by Drain.
+func (r *Recorder[V]) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.values)
+}
+
+// Drain returns all the values that have been recorded so far, like Snapshot, and removes them
+// from the Recorder, so that subsequent calls to Snapshot, Len, WaitForCount, and WaitFor only see
+// values that are added after this point. It does not affect the channel returned by Chan.
+func (r *Recorder[V]) Drain() []V {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := r.values
+	r.values = nil
+	r.drained += len(ret)
+	return ret
+}
+
+// Chan returns a channel that receives every value that is recorded, in order. The first call to
+// Chan creates the channel, and immediately delivers any values that were already recorded; later
+// calls return the same channel. This allows a Recorder to be used with channel helpers such as
+// RequireValue.
+//
+// Values are delivered to the channel regardless of whether they are also being inspected with
+// Snapshot, WaitFor, etc.
+func (r *Recorder[V]) Chan() <-chan V {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.ch == nil {
+		r.ch = make(chan V, recorderChanBufferSize)
+		if r.closed {
+			close(r.ch)
+		} else {
+			for _, v := range r.values {
+				r.deliver(v)
+			}
+		}
+	}
+	return r.ch
+}
+
+// Close stops delivering values to the channel returned by Chan, and closes the channel. Any values
+// that are already in the channel's buffer can still be received, but values that were still queued
+// are discarded. Values can still be added after Close, and will be visible to Snapshot, WaitFor,
+// etc., but not to the channel.
+//
+// It is only necessary to call Close if a test uses Chan but may not read every value from it,
+// since otherwise the background goroutine that delivers queued values would be left running.
+func (r *Recorder[V]) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.queue = nil
+	close(r.done)
+	if r.ch != nil && !r.feeding {
+		close(r.ch)
+	}
+	// If the feeder goroutine is running, it will close the channel when it wakes up
+}
+
+// WaitForCount waits until at least n values have been recorded, and returns a snapshot of the
+// values. If that does not happen before the timeout, it causes the test to fail and stop
+// immediately, describing the values that were received.
+func (r *Recorder[V]) WaitForCount(
+	t require.TestingT,
+	n int,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) []V {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	for {
+		r.lock.Lock()
+		if len(r.values) >= n {
+			ret := append([]V(nil), r.values...)
+			r.lock.Unlock()
+			return ret
+		}
+		changed := r.changed
+		r.lock.Unlock()
+		if !waitForSignal(changed, deadline) {
+			break
+		}
+	}
+	values := r.Snapshot()
+	var empty V
+	failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+		"expected %d %T value(s) from recorder but only received %d in %s%s",
+		n, empty, len(values), describeTimeout(timeout), describeReceivedValues(values))
+	t.FailNow()
+	return nil // never reached
+}
+
+// WaitFor waits until a value that matches the matcher has been recorded, and returns the first such
+// value. Values that were recorded before WaitFor was called are also checked. If there is no
+// matching value before the timeout, it causes the test to fail and stop immediately, describing
+// the non-matching values.
+//
+//	recorder.WaitFor(t, matchers.Equal("done"), time.Second)
+func (r *Recorder[V]) WaitFor(
+	t require.TestingT,
+	matcher matchers.Matcher,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) V {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	deadline := time.Now().Add(ScaledTimeout(timeout))
+	var discarded []string
+	checked := 0 // the total number of values we have seen, including any that were later drained
+	for {
+		r.lock.Lock()
+		start := max(0, checked-r.drained)
+		newValues := append([]V(nil), r.values[start:]...)
+		checked = r.drained + len(r.values)
+		changed := r.changed
+		r.lock.Unlock()
+		for _, v := range newValues {
+			pass, desc := matcher.Test(v)
+			if pass {
+				return v
+			}
+			discarded = append(discarded, describeDiscardedValue(v, desc))
+		}
+		if !waitForSignal(changed, deadline) {
+			break
+		}
+	}
+	var empty V
+	failWithTimeoutMessageAndArgs(t, customMessageAndArgs,
+		"expected a matching %T value from recorder but did not receive one in %s; %s",
+		empty, describeTimeout(timeout), describeDiscardedValues(discarded))
+	t.FailNow()
+	return empty // never reached
+}
+
+// deliver must be called while holding the lock.
+func (r *Recorder[V]) deliver(value V) {
+	if !r.feeding {
+		select {
+		case r.ch <- value:
+			return
+		default:
+		}
+		r.feeding = true
+		go r.feed()
+	}
+	r.queue = append(r.queue, value)
+}
+
+func (r *Recorder[V]) feed() {
+	for {
+		r.lock.Lock()
+		if r.closed {
+			r.feeding = false
+			close(r.ch)
+			r.lock.Unlock()
+			return
+		}
+		if len(r.queue) == 0 {
+			r.feeding = false
+			r.lock.Unlock()
+			return
+		}
+		value := r.queue[0]
+		r.lock.Unlock()
+		r.send(value)
+	}
+}
+
+func (r *Recorder[V]) send(value V) {
+	select {
+	case r.ch <- value:
+		r.lock.Lock()
+		if len(r.queue) != 0 {
+			r.queue = r.queue[1:]
+		}
+		r.lock.Unlock()
+	case <-r.done:
+	}
+}
+
+func waitForSignal(signal <-chan struct{}, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-signal:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func describeReceivedValues[V any](values []V) string {
+	if len(values) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, matchers.DescribeValue(v))
+	}
+	return ":\n" + strings.Join(parts, "\n")
+}
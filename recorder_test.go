@@ -0,0 +1,163 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderSnapshotLenAndDrain(t *testing.T) {
+	r := NewRecorder[string]()
+	assert.Equal(t, 0, r.Len())
+	assert.Len(t, r.Snapshot(), 0)
+
+	r.Add("a")
+	r.Add("b")
+	assert.Equal(t, 2, r.Len())
+	snapshot := r.Snapshot()
+	assert.Equal(t, []string{"a", "b"}, snapshot)
+
+	r.Add("c")
+	assert.Equal(t, []string{"a", "b"}, snapshot) // snapshot is a copy
+
+	assert.Equal(t, []string{"a", "b", "c"}, r.Drain())
+	assert.Equal(t, 0, r.Len())
+	r.Add("d")
+	assert.Equal(t, []string{"d"}, r.Snapshot())
+}
+
+func TestRecorderChanReceivesEarlierAndLaterValues(t *testing.T) {
+	r := NewRecorder[string]()
+	r.Add("a")
+	ch := r.Chan()
+	assert.Equal(t, ch, r.Chan())
+	r.Add("b")
+	assert.Equal(t, "a", RequireValue(t, ch, time.Second))
+	assert.Equal(t, "b", RequireValue(t, ch, time.Second))
+	AssertNoMoreValues(t, ch, time.Millisecond*10)
+}
+
+func TestRecorderAddDoesNotBlockIfChannelIsFull(t *testing.T) {
+	r := NewRecorder[int]()
+	ch := r.Chan()
+	count := recorderChanBufferSize * 3
+	for i := 0; i < count; i++ {
+		r.Add(i)
+	}
+	assert.Equal(t, count, r.Len())
+	for i := 0; i < count; i++ {
+		require.Equal(t, i, RequireValue(t, ch, time.Second))
+	}
+	AssertNoMoreValues(t, ch, time.Millisecond*10)
+}
+
+func TestRecorderClose(t *testing.T) {
+	r := NewRecorder[int]()
+	ch := r.Chan()
+	for i := 0; i < recorderChanBufferSize+10; i++ {
+		r.Add(i)
+	}
+	r.Close()
+	r.Close() // no-op
+	for i := 0; i < recorderChanBufferSize; i++ {
+		require.Equal(t, i, RequireValue(t, ch, time.Second))
+	}
+	AssertChannelClosed(t, ch, time.Second) // queued values beyond the buffer were discarded
+
+	r.Add(-1)
+	assert.Equal(t, recorderChanBufferSize+11, r.Len())
+
+	r2 := NewRecorder[int]()
+	r2.Close()
+	AssertChannelClosed(t, r2.Chan(), time.Second)
+}
+
+func TestRecorderWaitForCount(t *testing.T) {
+	r := NewRecorder[string]()
+	go func() {
+		r.Add("a")
+		time.Sleep(time.Millisecond * 10)
+		r.Add("b")
+	}()
+	assert.Equal(t, []string{"a", "b"}, r.WaitForCount(t, 2, time.Second))
+}
+
+func TestRecorderWaitForCountFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	r := NewRecorder[string]()
+	r.Add("a")
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		r.WaitForCount(t, 2, time.Millisecond*10)
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected 2 string value(s) from recorder but only received 1 in 10ms:\n\"a\"",
+		result.Failures[0].Message)
+}
+
+func TestRecorderWaitFor(t *testing.T) {
+	r := NewRecorder[string]()
+	r.Add("a")
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		r.Add("b")
+		r.Add("c")
+	}()
+	assert.Equal(t, "a", r.WaitFor(t, matchers.Equal("a"), time.Second))
+	assert.Equal(t, "c", r.WaitFor(t, matchers.Equal("c"), time.Second))
+}
+
+func TestRecorderWaitForSeesValuesAddedAfterDrain(t *testing.T) {
+	r := NewRecorder[string]()
+	r.Add("a")
+	r.Add("b")
+	// The matcher drains the recorder after WaitFor has seen both values, and then adds the same
+	// number of new values, so the length is unchanged.
+	matcher := matchers.New(
+		func(value any) bool {
+			if value == "b" {
+				r.Drain()
+				r.Add("c")
+				r.Add("d")
+			}
+			return value == "d"
+		},
+		func() string { return "d" },
+		nil,
+	)
+	assert.Equal(t, "d", r.WaitFor(t, matcher, time.Second))
+}
+
+func TestRecorderWaitForFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	r := NewRecorder[string]()
+	r.Add("b")
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		r.WaitFor(t, matchers.Equal("a"), time.Millisecond*10, "custom message")
+		t.Errorf("should not get here")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 2)
+	assert.Equal(t, "expected a matching string value from recorder but did not receive one in 10ms; "+
+		"discarded 1 non-matching value(s):\n\"b\" (did not equal \"a\")",
+		result.Failures[0].Message)
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+}
+
+func TestRecorderFeederGoroutineExits(t *testing.T) {
+	CheckGoroutineLeaks(t)
+	r := NewRecorder[int]()
+	ch := r.Chan()
+	for i := 0; i < recorderChanBufferSize+1; i++ {
+		r.Add(i)
+	}
+	for i := 0; i < recorderChanBufferSize+1; i++ {
+		RequireValue(t, ch, time.Second)
+	}
+}
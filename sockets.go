@@ -0,0 +1,470 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultConversationTimeout = time.Second * 5
+
+// ConversationEventKind is the type of a ConversationEvent.
+type ConversationEventKind string
+
+const (
+	// ConversationEventConnected means that a client connected to the server.
+	ConversationEventConnected ConversationEventKind = "connected"
+	// ConversationEventReceived means that the server received data from the client.
+	ConversationEventReceived ConversationEventKind = "received"
+	// ConversationEventSent means that the server sent data to the client.
+	ConversationEventSent ConversationEventKind = "sent"
+	// ConversationEventClosed means that the server closed the connection.
+	ConversationEventClosed ConversationEventKind = "closed"
+	// ConversationEventReset means that the server reset the connection.
+	ConversationEventReset ConversationEventKind = "reset"
+	// ConversationEventClientClosed means that the client closed the connection.
+	ConversationEventClientClosed ConversationEventKind = "client closed"
+)
+
+// ConversationEvent is something that happened on a connection to a ScriptedServer.
+type ConversationEvent struct {
+	// Connection identifies the connection: 1 for the first connection to the server, 2 for the
+	// second, etc.
+	Connection int
+	// Kind is the type of event.
+	Kind ConversationEventKind
+	// Data is the data that was received or sent, if Kind is ConversationEventReceived or
+	// ConversationEventSent.
+	Data []byte
+	// Time is when the event happened.
+	Time time.Time
+}
+
+// String returns a description of the event, such as `conn 1 received "PING\n"`.
+func (e ConversationEvent) String() string {
+	if e.Data != nil {
+		return fmt.Sprintf("conn %d %s %q", e.Connection, e.Kind, e.Data)
+	}
+	return fmt.Sprintf("conn %d %s", e.Connection, e.Kind)
+}
+
+// Conversation is a script for a ScriptedServer to follow on each connection: what data to
+// expect from the client, and what to do in response. Build one with NewConversation and its
+// methods, which can be chained:
+//
+//	conversation := helpers.NewConversation().
+//	    ExpectLine(matchers.Equal("PING")).
+//	    SendLine("PONG").
+//	    ExpectBytes([]byte("QUIT\n")).
+//	    Close()
+//
+// A Conversation should not be modified after it has been passed to a server.
+type Conversation struct {
+	steps   []conversationStep
+	timeout time.Duration
+}
+
+type conversationStep struct {
+	description string
+	run         func(c *scriptedConn) error
+	final       bool
+}
+
+// errServerShutDown is used internally to stop a connection's script when the server is closed.
+var errServerShutDown = errors.New("server was shut down")
+
+// NewConversation creates an empty Conversation.
+func NewConversation() *Conversation {
+	return &Conversation{timeout: defaultConversationTimeout}
+}
+
+// WithTimeout sets how long each Expect step will wait for data from the client before it is
+// reported as a failure. The default is 5 seconds. The timeout is scaled by ScaledTimeout.
+func (c *Conversation) WithTimeout(timeout time.Duration) *Conversation {
+	c.timeout = timeout
+	return c
+}
+
+// ExpectBytes adds a step that reads exactly as many bytes as there are in data, and fails if they
+// are not the same as data.
+func (c *Conversation) ExpectBytes(data []byte) *Conversation {
+	return c.add(fmt.Sprintf("expect bytes %q", data), func(sc *scriptedConn) error {
+		received, err := sc.reader.ReadN(len(data), c.timeout)
+		sc.recordReceived(received)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(received, data) {
+			return fmt.Errorf("received %q", received)
+		}
+		return nil
+	})
+}
+
+// ExpectLine adds a step that reads a line of text, and fails if it does not match the matcher.
+// The line that is tested does not include the line terminator, which can be "\n" or "\r\n".
+func (c *Conversation) ExpectLine(matcher matchers.Matcher) *Conversation {
+	return c.add(fmt.Sprintf("expect line (%s)", matcher.Describe()), func(sc *scriptedConn) error {
+		received, err := sc.reader.ReadUntil('\n', c.timeout)
+		sc.recordReceived(received)
+		if err != nil {
+			return err
+		}
+		line := strings.TrimSuffix(strings.TrimSuffix(string(received), "\n"), "\r")
+		if pass, desc := matcher.Test(line); !pass {
+			return errors.New(desc)
+		}
+		return nil
+	})
+}
+
+// Send adds a step that sends data to the client.
+func (c *Conversation) Send(data []byte) *Conversation {
+	return c.add(fmt.Sprintf("send %q", data), func(sc *scriptedConn) error {
+		return sc.send(data)
+	})
+}
+
+// SendLine adds a step that sends a line of text to the client, followed by "\n".
+func (c *Conversation) SendLine(line string) *Conversation {
+	return c.Send([]byte(line + "\n"))
+}
+
+// Delay adds a step that waits for the specified duration.
+func (c *Conversation) Delay(delay time.Duration) *Conversation {
+	return c.add(fmt.Sprintf("delay %s", delay), func(sc *scriptedConn) error {
+		select {
+		case <-time.After(delay):
+			return nil
+		case <-sc.server.shutdown:
+			return errServerShutDown
+		}
+	})
+}
+
+// Close adds a step that closes the connection. This ends the conversation; any further steps
+// are ignored.
+func (c *Conversation) Close() *Conversation {
+	return c.addFinal("close", func(sc *scriptedConn) error {
+		sc.record(ConversationEventClosed, nil)
+		return sc.conn.Close()
+	})
+}
+
+// Reset adds a step that closes the connection abruptly, so that the client gets a "connection
+// reset" error rather than an end of stream. This is only possible for TCP connections; for other
+// kinds of connections, it is the same as Close. This ends the conversation; any further steps
+// are ignored.
+func (c *Conversation) Reset() *Conversation {
+	return c.addFinal("reset", func(sc *scriptedConn) error {
+		if tcpConn, ok := sc.conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+		sc.record(ConversationEventReset, nil)
+		return sc.conn.Close()
+	})
+}
+
+func (c *Conversation) add(description string, run func(*scriptedConn) error) *Conversation {
+	c.steps = append(c.steps, conversationStep{description: description, run: run})
+	return c
+}
+
+func (c *Conversation) addFinal(description string, run func(*scriptedConn) error) *Conversation {
+	c.steps = append(c.steps, conversationStep{description: description, run: run, final: true})
+	return c
+}
+
+// ScriptedServer is a local server for testing code that uses a protocol other than HTTP. Every
+// connection to the server follows the same Conversation.
+//
+// Any step of the Conversation that fails, such as an Expect step that receives the wrong data or
+// times out, is reported as a test failure and ends that connection. Any data that is received
+// after the Conversation has ended without closing the connection is also reported as a failure,
+// as is the absence of any connection at all if the Conversation has any steps.
+//
+// Everything that happens on each connection is recorded, and can be inspected with Events.
+type ScriptedServer struct {
+	t            assert.TestingT
+	listener     net.Listener
+	conversation *Conversation
+	events       *Recorder[ConversationEvent]
+	shutdown     chan struct{}
+	conns        map[int]net.Conn
+	connCount    int
+	handlers     sync.WaitGroup
+	closeOnce    sync.Once
+	cleanup      func()
+	lock         sync.Mutex
+}
+
+type scriptedConn struct {
+	id     int
+	conn   net.Conn
+	reader *TimedReader
+	server *ScriptedServer
+}
+
+// TCPServer starts a ScriptedServer that listens on a TCP port on the local host, and closes it
+// when the test completes. Any failures of the Conversation are reported to the test at that time.
+// If the server cannot be started, the test fails and stops immediately.
+//
+//	server := helpers.TCPServer(t, helpers.NewConversation().ExpectLine(matchers.Equal("PING")).SendLine("PONG"))
+//	client := NewClient(server.Addr())
+func TCPServer(t CleanupT, conversation *Conversation) *ScriptedServer {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	s, err := startScriptedServer(t, "tcp", "127.0.0.1:0", conversation, nil)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+		return nil
+	}
+	t.Cleanup(s.close)
+	return s
+}
+
+// UnixSocketServer starts a ScriptedServer that listens on a Unix domain socket in a temporary
+// directory, and closes it when the test completes. Any failures of the Conversation are reported
+// to the test at that time. If the server cannot be started, the test fails and stops immediately.
+func UnixSocketServer(t CleanupT, conversation *Conversation) *ScriptedServer {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	s, err := startUnixSocketServer(t, conversation)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+		return nil
+	}
+	t.Cleanup(s.close)
+	return s
+}
+
+// WithTCPServer starts a ScriptedServer that listens on a TCP port on the local host, passes it to
+// the action, and then closes it. Any failures of the Conversation are reported to the test before
+// WithTCPServer returns. If the server cannot be started, the test fails and stops immediately,
+// without calling the action.
+//
+//	helpers.WithTCPServer(t, conversation, func(server *helpers.ScriptedServer) {
+//	    client := NewClient(server.Addr())
+//	    // ...
+//	})
+func WithTCPServer(t require.TestingT, conversation *Conversation, action func(*ScriptedServer)) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	s, err := startScriptedServer(t, "tcp", "127.0.0.1:0", conversation, nil)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+		return
+	}
+	defer s.close()
+	action(s)
+}
+
+// WithUnixSocketServer starts a ScriptedServer that listens on a Unix domain socket in a temporary
+// directory, passes it to the action, and then closes it. Any failures of the Conversation are
+// reported to the test before WithUnixSocketServer returns. If the server cannot be started, the
+// test fails and stops immediately, without calling the action.
+func WithUnixSocketServer(t require.TestingT, conversation *Conversation, action func(*ScriptedServer)) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	s, err := startUnixSocketServer(t, conversation)
+	if err != nil {
+		t.Errorf("%s", err)
+		t.FailNow()
+		return
+	}
+	defer s.close()
+	action(s)
+}
+
+func startUnixSocketServer(t assert.TestingT, conversation *Conversation) (*ScriptedServer, error) {
+	// Socket paths have a short maximum length, so we don't use the test's own temporary directory
+	dir, err := os.MkdirTemp("", "sock")
+	if err != nil {
+		return nil, fmt.Errorf("could not create directory for Unix socket: %w", err)
+	}
+	return startScriptedServer(t, "unix", filepath.Join(dir, "server.sock"), conversation,
+		func() { _ = os.RemoveAll(dir) })
+}
+
+func startScriptedServer(
+	t assert.TestingT,
+	network, address string,
+	conversation *Conversation,
+	cleanup func(),
+) (*ScriptedServer, error) {
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return nil, fmt.Errorf("could not start %s server: %w", network, err)
+	}
+	s := &ScriptedServer{
+		t:            t,
+		listener:     listener,
+		conversation: conversation,
+		events:       NewRecorder[ConversationEvent](),
+		shutdown:     make(chan struct{}),
+		conns:        make(map[int]net.Conn),
+		cleanup:      cleanup,
+	}
+	s.handlers.Add(1)
+	go s.acceptConnections()
+	return s, nil
+}
+
+// Addr returns the address that clients should connect to: a host and port for a TCP server, or a
+// file path for a Unix socket server.
+func (s *ScriptedServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Network returns the network name of the server, "tcp" or "unix", as used by net.Dial.
+func (s *ScriptedServer) Network() string {
+	return s.listener.Addr().Network()
+}
+
+// Events returns a Recorder of everything that has happened on all connections to the server, in
+// order. This can be used to make assertions about the timeline of the conversation, or to wait
+// for something to happen.
+//
+//	server.Events().WaitFor(t, matchers.Transform("kind", func(v any) (any, error) {
+//	    return v.(helpers.ConversationEvent).Kind, nil
+//	}).Should(matchers.Equal(helpers.ConversationEventClientClosed)), time.Second)
+func (s *ScriptedServer) Events() *Recorder[ConversationEvent] {
+	return s.events
+}
+
+func (s *ScriptedServer) acceptConnections() {
+	defer s.handlers.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return // the listener was closed
+		}
+		s.lock.Lock()
+		if s.isShutDown() {
+			s.lock.Unlock()
+			_ = conn.Close()
+			return
+		}
+		s.connCount++
+		sc := &scriptedConn{id: s.connCount, conn: conn, reader: NewTimedReader(conn), server: s}
+		s.conns[sc.id] = conn
+		s.handlers.Add(1)
+		s.lock.Unlock()
+		go sc.run()
+	}
+}
+
+func (s *ScriptedServer) close() {
+	s.closeOnce.Do(func() {
+		close(s.shutdown)
+		_ = s.listener.Close()
+		s.lock.Lock()
+		for _, conn := range s.conns {
+			_ = conn.Close()
+		}
+		connCount := s.connCount
+		s.lock.Unlock()
+		s.handlers.Wait()
+		if connCount == 0 && len(s.conversation.steps) != 0 {
+			s.t.Errorf("no connections were made to the %s server at %s", s.Network(), s.Addr())
+		}
+		if s.cleanup != nil {
+			s.cleanup()
+		}
+	})
+}
+
+func (s *ScriptedServer) isShutDown() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+func (sc *scriptedConn) run() {
+	s := sc.server
+	defer s.handlers.Done()
+	defer func() {
+		s.lock.Lock()
+		delete(s.conns, sc.id)
+		s.lock.Unlock()
+	}()
+	sc.record(ConversationEventConnected, nil)
+	for i, step := range s.conversation.steps {
+		if err := step.run(sc); err != nil {
+			if s.isShutDown() || errors.Is(err, errServerShutDown) {
+				s.t.Errorf("connection %d: server was closed before step %d (%s) was completed",
+					sc.id, i+1, step.description)
+			} else {
+				s.t.Errorf("connection %d: step %d (%s) failed: %s", sc.id, i+1, step.description, err)
+			}
+			_ = sc.conn.Close()
+			return
+		}
+		if step.final {
+			return
+		}
+	}
+	sc.expectNoMoreInput()
+	_ = sc.conn.Close()
+}
+
+// expectNoMoreInput waits for the client to close the connection or for the server to be closed,
+// and reports a failure if any more data is received first.
+func (sc *scriptedConn) expectNoMoreInput() {
+	data, err := sc.reader.readChunk(timedReaderChunkSize, time.Time{}) // a zero deadline means no deadline
+	switch {
+	case len(data) != 0:
+		sc.recordReceived(data)
+		sc.server.t.Errorf("connection %d: received unexpected data after the end of the conversation: %q",
+			sc.id, data)
+	case errors.Is(err, io.EOF):
+		sc.record(ConversationEventClientClosed, nil)
+	}
+}
+
+func (sc *scriptedConn) record(kind ConversationEventKind, data []byte) {
+	event := ConversationEvent{Connection: sc.id, Kind: kind, Time: time.Now()}
+	if data != nil {
+		event.Data = append([]byte(nil), data...)
+	}
+	sc.server.events.Add(event)
+}
+
+func (sc *scriptedConn) recordReceived(data []byte) {
+	if len(data) != 0 {
+		sc.record(ConversationEventReceived, data)
+	}
+}
+
+func (sc *scriptedConn) send(data []byte) error {
+	if _, err := sc.conn.Write(data); err != nil {
+		return err
+	}
+	sc.record(ConversationEventSent, data)
+	return nil
+}
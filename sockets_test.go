@@ -0,0 +1,311 @@
+package helpers
+
+import (
+	"errors"
+	"io"
+	"net"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialScriptedServer(t *testing.T, s *ScriptedServer) (net.Conn, *TimedReader) {
+	conn, err := net.Dial(s.Network(), s.Addr())
+	require.NoError(t, err)
+	return conn, NewTimedReader(conn)
+}
+
+func conversationEventsWithoutTimes(events []ConversationEvent) []ConversationEvent {
+	ret := make([]ConversationEvent, 0, len(events))
+	for _, e := range events {
+		e.Time = time.Time{}
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+func TestWithTCPServerSuccessfulConversation(t *testing.T) {
+	conversation := NewConversation().
+		ExpectLine(matchers.Equal("PING")).
+		SendLine("PONG").
+		ExpectBytes([]byte("QUIT\r\n")).
+		Close()
+
+	var events []ConversationEvent
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			assert.Equal(t, "tcp", s.Network())
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+
+			_, _ = conn.Write([]byte("PING\r\n"))
+			line, err := tr.ReadLine(time.Second)
+			require.NoError(t, err)
+			assert.Equal(t, "PONG", line)
+
+			_, _ = conn.Write([]byte("QUIT\r\n"))
+			_, err = tr.ReadAll(time.Second)
+			require.NoError(t, err)
+
+			events = s.Events().Snapshot()
+		})
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+
+	assert.Equal(t, []ConversationEvent{
+		{Connection: 1, Kind: ConversationEventConnected},
+		{Connection: 1, Kind: ConversationEventReceived, Data: []byte("PING\r\n")},
+		{Connection: 1, Kind: ConversationEventSent, Data: []byte("PONG\n")},
+		{Connection: 1, Kind: ConversationEventReceived, Data: []byte("QUIT\r\n")},
+		{Connection: 1, Kind: ConversationEventClosed},
+	}, conversationEventsWithoutTimes(events))
+}
+
+func TestWithUnixSocketServerSuccessfulConversation(t *testing.T) {
+	conversation := NewConversation().ExpectBytes([]byte("hello")).Send([]byte("bye"))
+
+	var path string
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithUnixSocketServer(st, conversation, func(s *ScriptedServer) {
+			assert.Equal(t, "unix", s.Network())
+			path = s.Addr()
+			conn, tr := dialScriptedServer(t, s)
+
+			_, _ = conn.Write([]byte("hello"))
+			data, err := tr.ReadN(3, time.Second)
+			require.NoError(t, err)
+			assert.Equal(t, "bye", string(data))
+
+			conn.Close()
+			s.Events().WaitFor(t, matchers.Transform("kind", func(v any) (any, error) {
+				return v.(ConversationEvent).Kind, nil
+			}).Should(matchers.Equal(ConversationEventClientClosed)), time.Second)
+		})
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+	assert.NoFileExists(t, path)
+}
+
+func TestTCPServerIsClosedAtCleanup(t *testing.T) {
+	var addr string
+	result := testbox.SandboxTestWithCleanup(func(st testbox.CleanupT) {
+		s := TCPServer(st, NewConversation().SendLine("hello"))
+		addr = s.Addr()
+		conn, tr := dialScriptedServer(t, s)
+		defer conn.Close()
+		line, err := tr.ReadLine(time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, "hello", line)
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+
+	_, err := net.Dial("tcp", addr)
+	assert.Error(t, err)
+}
+
+func TestUnixSocketServerIsClosedAtCleanup(t *testing.T) {
+	var path string
+	result := testbox.SandboxTestWithCleanup(func(st testbox.CleanupT) {
+		s := UnixSocketServer(st, NewConversation())
+		path = s.Addr()
+		assert.FileExists(t, path)
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+	assert.NoFileExists(t, path)
+}
+
+func TestUnixSocketServerCannotBeStarted(t *testing.T) {
+	t.Setenv("TMPDIR", filepath.Join(t.TempDir(), "nonexistent"))
+
+	result := testbox.SandboxTestWithCleanup(func(st testbox.CleanupT) {
+		UnixSocketServer(st, NewConversation())
+		st.Errorf("should not get here")
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Contains(t, result.Failures[0].Message, "could not create directory for Unix socket: ")
+
+	testbox.ShouldFailAndExitEarly(t, func(st testbox.TestingT) {
+		WithUnixSocketServer(st, NewConversation(), func(*ScriptedServer) {
+			st.Errorf("should not get here")
+		})
+	})
+}
+
+func TestScriptedServerReportsMismatchedLine(t *testing.T) {
+	conversation := NewConversation().ExpectLine(matchers.Equal("PING")).SendLine("PONG")
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, _ = conn.Write([]byte("PONG\n"))
+			_, err := tr.ReadAll(time.Second)
+			assert.NoError(t, err) // the server closes the connection after the failure
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: step 1 (expect line (equal to "PING")) failed: did not equal "PING"`+
+		"\n"+`full value was: "PONG"`, result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsMismatchedBytes(t *testing.T) {
+	conversation := NewConversation().ExpectBytes([]byte("abc"))
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, _ = conn.Write([]byte("abd"))
+			_, _ = tr.ReadAll(time.Second)
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: step 1 (expect bytes "abc") failed: received "abd"`, result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsTimeout(t *testing.T) {
+	conversation := NewConversation().WithTimeout(time.Millisecond * 50).ExpectLine(matchers.Equal("PING"))
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, _ = conn.Write([]byte("PI"))
+			_, _ = tr.ReadAll(time.Second)
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: step 1 (expect line (equal to "PING")) failed: `+
+		`read timed out after receiving 2 byte(s): "PI"`, result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsClientClosingEarly(t *testing.T) {
+	conversation := NewConversation().ExpectLine(matchers.Equal("PING"))
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, _ := dialScriptedServer(t, s)
+			conn.Close()
+			s.Events().WaitForCount(t, 1, time.Second)
+			time.Sleep(time.Millisecond * 50)
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: step 1 (expect line (equal to "PING")) failed: `+
+		`EOF after receiving 0 byte(s): ""`, result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsUnmetExpectationAtShutdown(t *testing.T) {
+	conversation := NewConversation().SendLine("hello").ExpectLine(matchers.Equal("PING"))
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, err := tr.ReadLine(time.Second)
+			require.NoError(t, err)
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: server was closed before step 2 (expect line (equal to "PING")) was completed`,
+		result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsUnexpectedInput(t *testing.T) {
+	conversation := NewConversation().ExpectLine(matchers.Equal("PING"))
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, _ = conn.Write([]byte("PING\nPING\n"))
+			_, _ = tr.ReadAll(time.Second)
+		})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, `connection 1: received unexpected data after the end of the conversation: "PING\n"`,
+		result.Failures[0].Message)
+}
+
+func TestScriptedServerReportsNoConnections(t *testing.T) {
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, NewConversation().SendLine("hello"), func(s *ScriptedServer) {})
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `^no connections were made to the tcp server at 127\.0\.0\.1:\d+$`, result.Failures[0].Message)
+
+	result = testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, NewConversation(), func(s *ScriptedServer) {})
+	})
+	assert.False(t, result.Failed)
+}
+
+func TestScriptedServerDelay(t *testing.T) {
+	delay := time.Millisecond * 100
+	conversation := NewConversation().Delay(delay).SendLine("hello").Close()
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			start := time.Now()
+			line, err := tr.ReadLine(time.Second)
+			require.NoError(t, err)
+			assert.Equal(t, "hello", line)
+			assert.GreaterOrEqual(t, int64(time.Since(start)), int64(delay))
+		})
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+}
+
+func TestScriptedServerReset(t *testing.T) {
+	conversation := NewConversation().ExpectLine(matchers.Equal("PING")).Reset()
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			conn, tr := dialScriptedServer(t, s)
+			defer conn.Close()
+			_, _ = conn.Write([]byte("PING\n"))
+			_, err := tr.ReadAll(time.Second)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, syscall.ECONNRESET), "expected connection reset, got %s", err)
+			assert.False(t, errors.Is(err, io.EOF))
+
+			events := s.Events().WaitForCount(t, 3, time.Second)
+			assert.Equal(t, ConversationEventReset, events[2].Kind)
+		})
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+}
+
+func TestScriptedServerRunsConversationOnEachConnection(t *testing.T) {
+	conversation := NewConversation().SendLine("hello").Close()
+
+	result := testbox.SandboxTest(func(st testbox.TestingT) {
+		WithTCPServer(st, conversation, func(s *ScriptedServer) {
+			for i := 0; i < 2; i++ {
+				conn, tr := dialScriptedServer(t, s)
+				data, err := tr.ReadAll(time.Second)
+				require.NoError(t, err)
+				assert.Equal(t, "hello\n", string(data))
+				conn.Close()
+			}
+			events := s.Events().WaitForCount(t, 6, time.Second)
+			assert.Equal(t, 2, events[5].Connection)
+		})
+	})
+	assert.False(t, result.Failed, "%+v", result.Failures)
+}
+
+func TestConversationEventString(t *testing.T) {
+	assert.Equal(t, `conn 1 received "PING\n"`,
+		ConversationEvent{Connection: 1, Kind: ConversationEventReceived, Data: []byte("PING\n")}.String())
+	assert.Equal(t, "conn 2 client closed", ConversationEvent{Connection: 2, Kind: ConversationEventClientClosed}.String())
+}
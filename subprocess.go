@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"regexp"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+
+	"github.com/stretchr/testify/require"
+)
+
+// SubprocessEnvVar is the name of the environment variable that RunInSubprocess sets in the
+// subprocess, to the name of the test being run. See IsSubprocess.
+const SubprocessEnvVar = "GO_TEST_HELPERS_SUBPROCESS"
+
+// SubprocessResult is the result of RunInSubprocess.
+//
+// Since it has JSON field tags, failure messages from matchers will show it as JSON. For convenient
+// use with matchers, see SubprocessExitCode, SubprocessStdout, and SubprocessStderr.
+type SubprocessResult struct {
+	// ExitCode is the exit code of the subprocess: 0 if the test passed, 1 if it failed without
+	// calling os.Exit, or whatever value the test passed to os.Exit.
+	ExitCode int `json:"exitCode"`
+	// Stdout is everything the subprocess wrote to standard output. This includes output from the
+	// Go test framework, such as "PASS" if the test did not exit early.
+	Stdout string `json:"stdout"`
+	// Stderr is everything the subprocess wrote to standard error.
+	Stderr string `json:"stderr"`
+	// Duration is how long the subprocess ran.
+	Duration time.Duration `json:"duration"`
+}
+
+// IsSubprocess returns true if the current process was started by RunInSubprocess.
+func IsSubprocess() bool {
+	return os.Getenv(SubprocessEnvVar) != ""
+}
+
+// RunInSubprocess runs a single test function in a separate process, and returns its exit code and
+// output. This allows testing code paths that call os.Exit, log.Fatal, or otherwise would end the
+// test binary.
+//
+// This works by running the current test binary again, with a -test.run parameter that selects
+// only the specified test, and with the environment variable GO_TEST_HELPERS_SUBPROCESS set. The
+// test function should call IsSubprocess to decide whether it is the parent (which makes
+// assertions about the result) or the subprocess (which does the code path being tested). The env
+// parameter can provide additional environment variables for the subprocess.
+//
+//	func TestFatalError(t *testing.T) {
+//	    if helpers.IsSubprocess() {
+//	        RunCommand("--bad-option") // this should call os.Exit(2)
+//	        return
+//	    }
+//	    result := helpers.RunInSubprocess(t, "TestFatalError", nil)
+//	    matchers.In(t).Assert(result, matchers.AllOf(
+//	        helpers.SubprocessExitCode().Should(matchers.Equal(2)),
+//	        helpers.SubprocessStderr().Should(matchers.StringContains("unknown option"))))
+//	}
+//
+// If the package has a TestMain function, it must call m.Run() in the subprocess as usual.
+//
+// If the subprocess cannot be started, or if RunInSubprocess is called from within a subprocess,
+// the test fails and stops immediately.
+func RunInSubprocess(t require.TestingT, testName string, env map[string]string) SubprocessResult {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	if IsSubprocess() {
+		t.Errorf("RunInSubprocess was called within a subprocess; the test should check IsSubprocess() first")
+		t.FailNow()
+		return SubprocessResult{}
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^"+regexp.QuoteMeta(testName)+"$")
+	cmd.Env = append(os.Environ(), SubprocessEnvVar+"="+testName)
+	for k, v := range env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	startTime := time.Now()
+	err := cmd.Run()
+	result := SubprocessResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(startTime),
+	}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Errorf("could not run test %s in subprocess: %s", testName, err)
+			t.FailNow()
+			return result
+		}
+		result.ExitCode = exitErr.ExitCode()
+	}
+	return result
+}
+
+// SubprocessExitCode is a MatcherTransform for testing the ExitCode of a SubprocessResult.
+func SubprocessExitCode() matchers.MatcherTransform {
+	return matchers.Transform("exit code", func(value any) (any, error) {
+		return value.(SubprocessResult).ExitCode, nil
+	}).EnsureInputValueType(SubprocessResult{})
+}
+
+// SubprocessStdout is a MatcherTransform for testing the Stdout of a SubprocessResult.
+func SubprocessStdout() matchers.MatcherTransform {
+	return matchers.Transform("stdout", func(value any) (any, error) {
+		return value.(SubprocessResult).Stdout, nil
+	}).EnsureInputValueType(SubprocessResult{})
+}
+
+// SubprocessStderr is a MatcherTransform for testing the Stderr of a SubprocessResult.
+func SubprocessStderr() matchers.MatcherTransform {
+	return matchers.Transform("stderr", func(value any) (any, error) {
+		return value.(SubprocessResult).Stderr, nil
+	}).EnsureInputValueType(SubprocessResult{})
+}
@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInSubprocessWithExit(t *testing.T) {
+	if IsSubprocess() {
+		fmt.Println("to stdout")
+		fmt.Fprintln(os.Stderr, "to stderr: "+os.Getenv("EXTRA_VAR"))
+		os.Exit(3)
+	}
+	result := RunInSubprocess(t, "TestRunInSubprocessWithExit", map[string]string{"EXTRA_VAR": "hello"})
+	assert.Equal(t, 3, result.ExitCode)
+	assert.Equal(t, "to stdout\n", result.Stdout)
+	assert.Equal(t, "to stderr: hello\n", result.Stderr)
+	assert.Greater(t, int64(result.Duration), int64(0))
+
+	matchers.In(t).Assert(result, matchers.AllOf(
+		SubprocessExitCode().Should(matchers.Equal(3)),
+		SubprocessStdout().Should(matchers.StringContains("stdout")),
+		SubprocessStderr().Should(matchers.StringContains("hello")),
+	))
+}
+
+func TestRunInSubprocessWithTestFailure(t *testing.T) {
+	if IsSubprocess() {
+		t.Errorf("deliberate failure")
+		return
+	}
+	result := RunInSubprocess(t, "TestRunInSubprocessWithTestFailure", nil)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stdout, "deliberate failure")
+}
+
+func TestRunInSubprocessWithinSubprocess(t *testing.T) {
+	if IsSubprocess() {
+		result := testbox.SandboxTest(func(t testbox.TestingT) {
+			RunInSubprocess(t, "TestRunInSubprocessWithinSubprocess", nil)
+		})
+		if result.Failed {
+			os.Exit(5)
+		}
+		return
+	}
+	result := RunInSubprocess(t, "TestRunInSubprocessWithinSubprocess", nil)
+	assert.Equal(t, 5, result.ExitCode)
+}
+
+func TestSubprocessMatcherFailureDescription(t *testing.T) {
+	result := SubprocessResult{ExitCode: 1, Stdout: "x", Duration: time.Second}
+	pass, desc := SubprocessExitCode().Should(matchers.Equal(2)).Test(result)
+	require.False(t, pass)
+	assert.Equal(t, "exit code did not equal 2\nfull value was: "+
+		`{"duration":1000000000,"exitCode":1,"stderr":"","stdout":"x"}`, desc)
+}
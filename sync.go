@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RequireWaitGroupDone waits for the WaitGroup's counter to reach zero. If that does not happen
+// within the timeout, the test fails and stops immediately; the failure message includes the stack
+// traces of all goroutines that are running code from the module under test, to help diagnose a
+// deadlock.
+//
+//	var wg sync.WaitGroup
+//	wg.Add(2)
+//	go worker(&wg)
+//	go worker(&wg)
+//	helpers.RequireWaitGroupDone(t, &wg, time.Second)
+//
+// Since there is no way to cancel a call to WaitGroup.Wait, a failure leaves a goroutine that is
+// still waiting.
+func RequireWaitGroupDone(t require.TestingT, wg *sync.WaitGroup, timeout time.Duration, customMessageAndArgs ...any) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	done := make(chan struct{})
+	waiterID := make(chan int64, 1)
+	go func() {
+		waiterID <- getCurrentGoroutineID()
+		wg.Wait()
+		close(done)
+	}()
+	if waitForSignal(done, time.Now().Add(ScaledTimeout(timeout))) {
+		return
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs,
+		"expected WaitGroup to be done within %s, but it was not\n\n%s",
+		describeTimeout(timeout), describeModuleGoroutines(getCurrentGoroutineID(), <-waiterID))
+	t.FailNow()
+}
+
+// RequireLockAcquired acquires the lock, which can be any sync.Locker such as a *sync.Mutex. If it
+// cannot do so within the timeout, the test fails and stops immediately; the failure message
+// includes the stack traces of all goroutines that are running code from the module under test, to
+// help diagnose a deadlock.
+//
+// If successful, the lock is held when RequireLockAcquired returns, and the caller is responsible
+// for unlocking it. If it fails, the lock will be unlocked right away if it is eventually acquired.
+//
+//	helpers.RequireLockAcquired(t, &cache.mutex, time.Second)
+//	defer cache.mutex.Unlock()
+func RequireLockAcquired(t require.TestingT, l sync.Locker, timeout time.Duration, customMessageAndArgs ...any) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	acquired := make(chan struct{})
+	abandoned := make(chan struct{})
+	waiterID := make(chan int64, 1)
+	go func() {
+		waiterID <- getCurrentGoroutineID()
+		l.Lock()
+		select {
+		case acquired <- struct{}{}:
+		case <-abandoned:
+			l.Unlock()
+		}
+	}()
+	timer := time.NewTimer(ScaledTimeout(timeout))
+	defer timer.Stop()
+	select {
+	case <-acquired:
+		return
+	case <-timer.C:
+		close(abandoned)
+	}
+	failWithMessageAndArgs(t, customMessageAndArgs,
+		"could not acquire %T within %s\n\n%s",
+		l, describeTimeout(timeout), describeModuleGoroutines(getCurrentGoroutineID(), <-waiterID))
+	t.FailNow()
+}
+
+// RequireSignal waits on the sync.Cond until the predicate returns true, calling the predicate
+// first and then again each time the Cond is signalled. The predicate is called while cond.L is
+// locked, and cond.L is unlocked when RequireSignal returns.
+//
+// If the predicate has not returned true within the timeout, the test fails and stops immediately;
+// the failure message includes the stack traces of all goroutines that are running code from the
+// module under test, to help diagnose a deadlock. In order to stop waiting, RequireSignal calls
+// Broadcast on the Cond when the timeout expires, so any other goroutines that are waiting on it
+// will also wake up.
+//
+//	helpers.RequireSignal(t, queue.cond, func() bool { return len(queue.items) == 3 }, time.Second)
+func RequireSignal(
+	t require.TestingT,
+	cond *sync.Cond,
+	predicate func() bool,
+	timeout time.Duration,
+	customMessageAndArgs ...any,
+) {
+	if t, ok := t.(interface{ Helper() }); ok {
+		t.Helper()
+	}
+	timedOut := false
+	timer := time.AfterFunc(ScaledTimeout(timeout), func() {
+		cond.L.Lock()
+		timedOut = true
+		cond.L.Unlock()
+		cond.Broadcast()
+	})
+	defer timer.Stop()
+	cond.L.Lock()
+	for !predicate() {
+		if timedOut {
+			cond.L.Unlock()
+			failWithMessageAndArgs(t, customMessageAndArgs,
+				"expected condition to become true within %s, but it did not\n\n%s",
+				describeTimeout(timeout), describeModuleGoroutines(getCurrentGoroutineID()))
+			t.FailNow()
+			return
+		}
+		cond.Wait()
+	}
+	cond.L.Unlock()
+}
@@ -0,0 +1,107 @@
+package helpers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireWaitGroupDoneSuccess(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		wg.Done()
+	}()
+	RequireWaitGroupDone(t, &wg, time.Second)
+}
+
+func TestRequireWaitGroupDoneFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	release := make(chan struct{})
+	defer close(release)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		leakyGoroutine(release)
+	}()
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequireWaitGroupDone(t, &wg, time.Millisecond*10, "custom %s", "message")
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 2)
+	assert.Regexp(t, `^expected WaitGroup to be done within 10ms, but it was not\n\n`+
+		`\d+ goroutine\(s\) running code from github.com/launchdarkly/go-test-helpers/v3:\n\ngoroutine \d+ `,
+		result.Failures[0].Message)
+	assert.Contains(t, result.Failures[0].Message, "v3.leakyGoroutine(")
+	assert.NotContains(t, result.Failures[0].Message, "v3.RequireWaitGroupDone")
+	assert.Equal(t, "custom message", result.Failures[1].Message)
+}
+
+func TestRequireLockAcquiredSuccess(t *testing.T) {
+	var mutex sync.Mutex
+	mutex.Lock()
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		mutex.Unlock()
+	}()
+	RequireLockAcquired(t, &mutex, time.Second)
+	assert.False(t, mutex.TryLock())
+	mutex.Unlock()
+}
+
+func TestRequireLockAcquiredFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	var mutex sync.Mutex
+	mutex.Lock()
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequireLockAcquired(t, &mutex, time.Millisecond*10)
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `^could not acquire \*sync.Mutex within 10ms\n\n`, result.Failures[0].Message)
+	assert.NotContains(t, result.Failures[0].Message, "v3.RequireLockAcquired")
+
+	// the abandoned attempt to lock should not leave the mutex locked
+	mutex.Unlock()
+	RequireLockAcquired(t, &mutex, time.Second)
+	mutex.Unlock()
+}
+
+func TestRequireSignalSuccess(t *testing.T) {
+	var mutex sync.Mutex
+	cond := sync.NewCond(&mutex)
+	count := 0
+	go func() {
+		for i := 0; i < 3; i++ {
+			time.Sleep(time.Millisecond)
+			mutex.Lock()
+			count++
+			mutex.Unlock()
+			cond.Broadcast()
+		}
+	}()
+	RequireSignal(t, cond, func() bool { return count == 3 }, time.Second)
+	assert.True(t, mutex.TryLock())
+	mutex.Unlock()
+}
+
+func TestRequireSignalFailure(t *testing.T) {
+	withTimeoutScale(t, 1)
+	var mutex sync.Mutex
+	cond := sync.NewCond(&mutex)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		RequireSignal(t, cond, func() bool { return false }, time.Millisecond*10)
+	})
+	require.True(t, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Regexp(t, `^expected condition to become true within 10ms, but it did not\n\n`, result.Failures[0].Message)
+	assert.True(t, mutex.TryLock())
+	mutex.Unlock()
+}
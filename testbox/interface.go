@@ -40,6 +40,9 @@ import "github.com/stretchr/testify/require"
 // TestingT includes the same subsets of testing.T methods that are defined in the TestingT interfaces
 // of github.com/stretchr/testify/assert and github.com/stretchr/testify/require, so all assertions in
 // those packages will work. It also provides Run, Skip, and SkipNow. It does not support Parallel.
+//
+// TestingT does not include Cleanup, so that existing implementations of TestingT are still valid.
+// For test logic that needs it, use CleanupT instead.
 type TestingT interface {
 	require.TestingT
 	// Run runs a subtest with a new TestingT that applies only to the scope of the subtest. It is
@@ -61,3 +64,15 @@ type TestingT interface {
 	// SkipNow marks the test as skipped and exits early. It is equivalent to the same method in testing.T.
 	SkipNow()
 }
+
+// CleanupT is a TestingT that also provides Cleanup. It satisfies helpers.CleanupT, so it can be
+// passed to test-scoped helpers such as helpers.TempDir. To get one, use SandboxTestWithCleanup or
+// RealTestWithCleanup.
+type CleanupT interface {
+	TestingT
+
+	// Cleanup registers a function to be called when the test and all its subtests complete. Cleanup
+	// functions are called in last-added, first-called order. It is equivalent to the same method in
+	// testing.T.
+	Cleanup(f func())
+}
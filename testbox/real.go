@@ -13,6 +13,11 @@ func RealTest(t *testing.T) TestingT {
 	return realTestingT{t}
 }
 
+// RealTestWithCleanup is the same as RealTest, but returns a CleanupT.
+func RealTestWithCleanup(t *testing.T) CleanupT {
+	return realTestingT{t}
+}
+
 func (r realTestingT) Errorf(format string, args ...any) {
 	r.t.Errorf(format, args...) // COVERAGE: can't do this in test_sandbox_test; it'll cause a real failure
 }
@@ -36,3 +41,7 @@ func (r realTestingT) Skip(args ...any) {
 func (r realTestingT) SkipNow() {
 	r.t.SkipNow()
 }
+
+func (r realTestingT) Cleanup(f func()) {
+	r.t.Cleanup(f)
+}
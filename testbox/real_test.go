@@ -78,4 +78,14 @@ func TestRealTest(t *testing.T) {
 		assert.False(t, t.Failed())
 		assert.False(t, t.Skipped())
 	})
+
+	t.Run("cleanup", func(t *testing.T) {
+		var calls []string
+		t.Run("sub", func(t *testing.T) {
+			rt := RealTestWithCleanup(t)
+			rt.Cleanup(func() { calls = append(calls, "first") })
+			rt.Cleanup(func() { calls = append(calls, "second") })
+		})
+		assert.Equal(t, []string{"second", "first"}, calls)
+	})
 }
@@ -52,8 +52,9 @@ type LogItem struct {
 
 type mockTestingT struct {
 	testState
-	path TestPath
-	lock sync.Mutex
+	path     TestPath
+	cleanups []func()
+	lock     sync.Mutex
 }
 
 // SandboxTest runs a test function against a TestingT instance that applies only to the scope of
@@ -66,6 +67,10 @@ type mockTestingT struct {
 // implement TestingT itself, is that the function must be run on a separate goroutine so that
 // the sandbox can intercept any early exits from FailNow or SkipNow.
 //
+// The TestingT also has a Cleanup method (see SandboxTestWithCleanup). Any functions registered with
+// Cleanup are called after the test function exits, each on its own
+// goroutine, so a FailNow within a cleanup function does not prevent the others from running.
+//
 // SandboxTest does not recover from panics.
 //
 // See TestingT for more details.
@@ -81,6 +86,18 @@ func SandboxTest(action func(TestingT)) SandboxResult {
 	}
 }
 
+// SandboxTestWithCleanup is the same as SandboxTest, but passes a CleanupT to the test function, so
+// that it can use test-scoped helpers such as helpers.TempDir. Functions registered with Cleanup are
+// called as described for SandboxTest.
+//
+//	result := testbox.SandboxTestWithCleanup(func(t testbox.CleanupT) {
+//	    dir := helpers.TempDir(t)
+//	    // ...
+//	})
+func SandboxTestWithCleanup(action func(CleanupT)) SandboxResult {
+	return SandboxTest(func(t TestingT) { action(t.(*mockTestingT)) })
+}
+
 func (m *mockTestingT) Errorf(format string, args ...any) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -125,6 +142,12 @@ func (m *mockTestingT) SkipNow() {
 	m.Skip()
 }
 
+func (m *mockTestingT) Cleanup(f func()) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.cleanups = append(m.cleanups, f)
+}
+
 func (m *mockTestingT) getState() testState {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -141,12 +164,27 @@ func (m *mockTestingT) getState() testState {
 }
 
 func (m *mockTestingT) runSafely(action func(TestingT)) {
+	runOnGoroutine(func() { action(m) })
+	for {
+		m.lock.Lock()
+		if len(m.cleanups) == 0 {
+			m.lock.Unlock()
+			return
+		}
+		cleanup := m.cleanups[len(m.cleanups)-1]
+		m.cleanups = m.cleanups[:len(m.cleanups)-1]
+		m.lock.Unlock()
+		runOnGoroutine(cleanup)
+	}
+}
+
+func runOnGoroutine(action func()) {
 	exited := make(chan struct{}, 1)
 	go func() {
 		defer func() {
 			close(exited)
 		}()
-		action(m)
+		action()
 	}()
 	<-exited
 }
@@ -241,6 +241,57 @@ func TestSandboxTestSubtests(t *testing.T) {
 	})
 }
 
+func TestSandboxTestCleanup(t *testing.T) {
+	t.Run("cleanups run in reverse order after test", func(t *testing.T) {
+		var calls []string
+		r := SandboxTestWithCleanup(func(u CleanupT) {
+			u.Cleanup(func() { calls = append(calls, "first") })
+			u.Cleanup(func() { calls = append(calls, "second") })
+			calls = append(calls, "test")
+		})
+		assert.Equal(t, []string{"test", "second", "first"}, calls)
+		assert.False(t, r.Failed)
+	})
+
+	t.Run("cleanups run after early exit", func(t *testing.T) {
+		var calls []string
+		r := SandboxTestWithCleanup(func(u CleanupT) {
+			u.Cleanup(func() { calls = append(calls, "cleanup") })
+			u.FailNow()
+		})
+		assert.Equal(t, []string{"cleanup"}, calls)
+		assert.True(t, r.Failed)
+	})
+
+	t.Run("cleanup can fail the test", func(t *testing.T) {
+		var calls []string
+		r := SandboxTestWithCleanup(func(u CleanupT) {
+			u.Cleanup(func() { calls = append(calls, "first") })
+			u.Cleanup(func() {
+				u.Errorf("cleanup failed")
+				u.FailNow()
+			})
+		})
+		assert.Equal(t, []string{"first"}, calls)
+		assert.True(t, r.Failed)
+		if assert.Len(t, r.Failures, 1) {
+			assert.Equal(t, "cleanup failed", r.Failures[0].Message)
+		}
+	})
+
+	t.Run("subtest cleanups run at end of subtest", func(t *testing.T) {
+		var calls []string
+		_ = SandboxTestWithCleanup(func(u CleanupT) {
+			u.Cleanup(func() { calls = append(calls, "parent cleanup") })
+			u.Run("sub", func(uu TestingT) {
+				uu.(*mockTestingT).Cleanup(func() { calls = append(calls, "sub cleanup") })
+			})
+			calls = append(calls, "after sub")
+		})
+		assert.Equal(t, []string{"sub cleanup", "after sub", "parent cleanup"}, calls)
+	})
+}
+
 func TestShouldFail(t *testing.T) {
 	ShouldFail(t, func(t TestingT) {
 		t.Errorf("boo")
@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutScaleEnvVar is the name of an environment variable that can be set to a number to multiply
+// all timeouts by. See ScaledTimeout.
+const TimeoutScaleEnvVar = "GO_TEST_HELPERS_TIMEOUT_SCALE"
+
+// This holds the bits of a float64 set by SetTimeoutScale, or zero if it has not been set.
+//
+//nolint:gochecknoglobals // the timeout scale is deliberately process-wide, like the environment variable
+var timeoutScaleOverride atomic.Uint64
+
+// TimeoutScale returns the number that timeouts are multiplied by. This is the value that was set
+// by SetTimeoutScale, if any; otherwise, the value of the GO_TEST_HELPERS_TIMEOUT_SCALE
+// environment variable, if it is a positive number; otherwise 1.
+func TimeoutScale() float64 {
+	if bits := timeoutScaleOverride.Load(); bits != 0 {
+		return math.Float64frombits(bits)
+	}
+	if scale, err := strconv.ParseFloat(os.Getenv(TimeoutScaleEnvVar), 64); err == nil && scale > 0 {
+		return scale
+	}
+	return 1
+}
+
+// SetTimeoutScale sets the number that timeouts are multiplied by, overriding the
+// GO_TEST_HELPERS_TIMEOUT_SCALE environment variable. A value of zero or less removes the override.
+//
+// Since this is global, it is best to call it from TestMain rather than from individual tests.
+//
+//	func TestMain(m *testing.M) {
+//	    if raceDetectorEnabled {
+//	        helpers.SetTimeoutScale(3)
+//	    }
+//	    os.Exit(m.Run())
+//	}
+func SetTimeoutScale(scale float64) {
+	if scale > 0 {
+		timeoutScaleOverride.Store(math.Float64bits(scale))
+	} else {
+		timeoutScaleOverride.Store(0)
+	}
+}
+
+// ScaledTimeout returns the duration multiplied by TimeoutScale. This lets tests that are run on a
+// slow machine, or with the race detector, allow more time for things to happen without changing
+// the timeouts in the code.
+//
+// All of the helpers in this package that take a timeout (or a duration in which something should
+// not happen) apply the scale automatically, and their failure messages show both the original and
+// the scaled timeout; so do the helpers in mockcall that wait for a call. The exception is that durations measured with a
+// FakeClock are not scaled, since they are not real time. Tests that use timeouts in their own
+// code can call ScaledTimeout directly.
+//
+//	select {
+//	case <-done:
+//	case <-time.After(helpers.ScaledTimeout(time.Second)):
+//	    t.Fatal("timed out")
+//	}
+func ScaledTimeout(d time.Duration) time.Duration {
+	scale := TimeoutScale()
+	if scale == 1 {
+		return d
+	}
+	return time.Duration(float64(d) * scale)
+}
+
+// describeTimeout returns a string like "1s", or "1s (scaled to 3s)" if the timeout is scaled.
+func describeTimeout(d time.Duration) string {
+	if scaled := ScaledTimeout(d); scaled != d {
+		return fmt.Sprintf("%s (scaled to %s)", d, scaled)
+	}
+	return d.String()
+}
+
+// scaledTimeoutForClock is the same as ScaledTimeout, except that it does not scale durations that
+// are measured with a fake clock.
+func scaledTimeoutForClock(clock Clock, d time.Duration) time.Duration {
+	if _, ok := clock.(realClock); ok {
+		return ScaledTimeout(d)
+	}
+	return d
+}
+
+func describeTimeoutForClock(clock Clock, d time.Duration) string {
+	if _, ok := clock.(realClock); ok {
+		return describeTimeout(d)
+	}
+	return d.String()
+}
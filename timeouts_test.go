@@ -0,0 +1,84 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/launchdarkly/go-test-helpers/v3/matchers"
+	"github.com/launchdarkly/go-test-helpers/v3/testbox"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withTimeoutScale overrides the timeout scale for the duration of a test. Tests that check the exact
+// text of a failure message that includes a timeout call withTimeoutScale(t, 1), so that they still
+// pass if GO_TEST_HELPERS_TIMEOUT_SCALE is set for the test run.
+func withTimeoutScale(t *testing.T, scale float64) {
+	SetTimeoutScale(scale)
+	t.Cleanup(func() { SetTimeoutScale(0) })
+}
+
+func TestTimeoutScaleDefault(t *testing.T) {
+	t.Setenv(TimeoutScaleEnvVar, "")
+	assert.Equal(t, 1.0, TimeoutScale())
+	assert.Equal(t, time.Second, ScaledTimeout(time.Second))
+	assert.Equal(t, "1s", describeTimeout(time.Second))
+}
+
+func TestTimeoutScaleFromEnvironment(t *testing.T) {
+	t.Setenv(TimeoutScaleEnvVar, "2.5")
+	assert.Equal(t, 2.5, TimeoutScale())
+	assert.Equal(t, time.Millisecond*2500, ScaledTimeout(time.Second))
+	assert.Equal(t, "1s (scaled to 2.5s)", describeTimeout(time.Second))
+
+	for _, badValue := range []string{"x", "0", "-1"} {
+		t.Setenv(TimeoutScaleEnvVar, badValue)
+		assert.Equal(t, 1.0, TimeoutScale(), badValue)
+	}
+}
+
+func TestSetTimeoutScaleOverridesEnvironment(t *testing.T) {
+	t.Setenv(TimeoutScaleEnvVar, "2")
+	withTimeoutScale(t, 3)
+	assert.Equal(t, 3.0, TimeoutScale())
+	assert.Equal(t, time.Second*3, ScaledTimeout(time.Second))
+
+	SetTimeoutScale(0)
+	assert.Equal(t, 2.0, TimeoutScale())
+}
+
+func TestTimeoutScaleIsNotAppliedToFakeClock(t *testing.T) {
+	withTimeoutScale(t, 3)
+	clock := NewFakeClock(time.Now())
+	assert.Equal(t, time.Second, scaledTimeoutForClock(clock, time.Second))
+	assert.Equal(t, "1s", describeTimeoutForClock(clock, time.Second))
+	assert.Equal(t, time.Second*3, scaledTimeoutForClock(RealClock(), time.Second))
+	assert.Equal(t, "1s (scaled to 3s)", describeTimeoutForClock(RealClock(), time.Second))
+}
+
+func TestScaledTimeoutIsAppliedByHelpers(t *testing.T) {
+	withTimeoutScale(t, 20)
+	ch := make(chan string, 1)
+	go func() {
+		time.Sleep(time.Millisecond * 50)
+		ch <- "a"
+	}()
+	assert.Equal(t, "a", RequireValue(t, ch, time.Millisecond*10))
+}
+
+func TestScaledTimeoutIsShownInFailureMessages(t *testing.T) {
+	withTimeoutScale(t, 2)
+	result := testbox.SandboxTest(func(t testbox.TestingT) {
+		_ = RequireValue(t, make(chan string), time.Millisecond*5)
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "expected a string value from channel but did not receive one in 5ms (scaled to 10ms)",
+		result.Failures[0].Message)
+
+	result = testbox.SandboxTest(func(t testbox.TestingT) {
+		Eventually(t, func() int { return 0 }, matchers.Equal(1), time.Millisecond*5, time.Millisecond)
+	})
+	require.Len(t, result.Failures, 1)
+	assert.Contains(t, result.Failures[0].Message, "expected value to match within 5ms (scaled to 10ms)")
+}